@@ -0,0 +1,425 @@
+package opencloud
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrBlobNotFound is returned when a blob does not have a manifest.
+	ErrBlobNotFound = errors.New("blob not found")
+	// ErrBlobHashMismatch is returned when the data read for a blob does not match the hash in its manifest.
+	ErrBlobHashMismatch = errors.New("blob hash does not match its manifest")
+
+	blobChunkKeyRegex = regexp.MustCompile(`^(.+)\.c\.(([0-9a-z]+)-[0-9a-f]+)\.\d+$`)
+)
+
+// BlobManifest is stored under the key of a blob, and describes the chunks that hold its data.
+type BlobManifest struct {
+	// Size is the size of the data before it was compressed.
+	Size int `json:"size"`
+	// SHA256 is the hash of the data before it was compressed.
+	SHA256 string `json:"sha256"`
+	// Generation is unique for every write, so the chunks of a new write never replace the chunks of the current one.
+	Generation  string   `json:"generation"`
+	ChunkCount  int      `json:"chunkCount"`
+	ChunkHashes []string `json:"chunkHashes"`
+	CreateTime  string   `json:"createTime"`
+}
+
+// BlobStore stores values that are larger than the data store entry size limit by splitting them across multiple entries.
+//
+// A value is compressed and split into chunks stored under "{key}.c.{generation}.{index}", and a manifest is stored under the key.
+// The manifest is committed after every chunk was written, so readers never see a partially written value.
+type BlobStore struct {
+	service     *DataAndMemoryStoreService
+	universeId  string
+	dataStoreId string
+	scope       *string
+
+	// ChunkSize is the maximum amount of compressed bytes stored in a chunk.
+	// Chunks are base64 encoded, so this has to stay below three quarters of the entry size limit.
+	ChunkSize int
+	// Concurrency is the amount of chunks that are read or written at the same time.
+	Concurrency int
+	// GarbageCollectionGrace protects chunks that are younger than the grace period from CollectGarbage, so writes in progress are not removed.
+	GarbageCollectionGrace time.Duration
+}
+
+// NewBlobStore will create a blob store in a specific data store under a specific universe.
+func (s *DataAndMemoryStoreService) NewBlobStore(universeId, dataStoreId string, scope *string) *BlobStore {
+	return &BlobStore{
+		service:                s,
+		universeId:             universeId,
+		dataStoreId:            dataStoreId,
+		scope:                  scope,
+		ChunkSize:              3_000_000,
+		Concurrency:            4,
+		GarbageCollectionGrace: time.Hour,
+	}
+}
+
+func blobChunkKey(key, generation string, index int) string {
+	return fmt.Sprintf("%s.c.%s.%d", key, generation, index)
+}
+
+func newBlobGeneration() (string, error) {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	// The creation time is kept for CollectGarbage, and the random part keeps concurrent writes of a key apart.
+	return fmt.Sprintf("%s-%s", strconv.FormatInt(time.Now().Unix(), 36), hex.EncodeToString(random)), nil
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// manifest will fetch the manifest of a blob and its etag.
+func (b *BlobStore) manifest(ctx context.Context, key string) (*BlobManifest, string, error) {
+	entry, resp, err := b.service.GetDataStoreEntry(ctx, b.universeId, b.dataStoreId, b.scope, key)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isStatus(err, http.StatusNotFound) {
+		return nil, "", ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	manifest := new(BlobManifest)
+	if err := decodeValue(entry.Value, manifest); err != nil {
+		return nil, "", err
+	}
+
+	return manifest, entry.Etag, nil
+}
+
+// forEachChunk will call fn for every chunk index, running up to Concurrency calls at the same time and returning the first error.
+func (b *BlobStore) forEachChunk(ctx context.Context, count int, fn func(ctx context.Context, index int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	semaphore := make(chan struct{}, max(b.Concurrency, 1))
+	for index := range count {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := fn(ctx, index); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return ctx.Err()
+}
+
+// Put will store the data under the key, replacing any previous value once every chunk was written.
+//
+// A ValidationError is returned before anything is written if the chunk keys would not fit within the entry key length limit.
+func (b *BlobStore) Put(ctx context.Context, key string, data []byte) (*BlobManifest, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	generation, err := newBlobGeneration()
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks [][]byte
+	for remaining := compressed.Bytes(); len(remaining) > 0; {
+		n := min(b.ChunkSize, len(remaining))
+		chunks = append(chunks, remaining[:n])
+		remaining = remaining[n:]
+	}
+
+	v := new(validation)
+	if n := utf8.RuneCountInString(blobChunkKey(key, generation, max(len(chunks)-1, 0))); n > MaxDataStoreEntryKeyLength {
+		v.add("key", "chunk keys are %d characters long, which exceeds the limit of %d characters", n, MaxDataStoreEntryKeyLength)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	manifest := &BlobManifest{
+		Size:        len(data),
+		SHA256:      sha256Hex(data),
+		Generation:  generation,
+		ChunkCount:  len(chunks),
+		ChunkHashes: make([]string, len(chunks)),
+		CreateTime:  time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	for i, chunk := range chunks {
+		manifest.ChunkHashes[i] = sha256Hex(chunk)
+	}
+
+	err = b.forEachChunk(ctx, len(chunks), func(ctx context.Context, index int) error {
+		var value any = base64.StdEncoding.EncodeToString(chunks[index])
+		_, resp, err := b.service.CreateDataStoreEntry(ctx, b.universeId, b.dataStoreId, b.scope, DataStoreEntryCreate{
+			Value: &value,
+		}, &DataStoreEntryCreateOptions{ID: Pointer(blobChunkKey(key, generation, index))})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+
+		return err
+	})
+	if err != nil {
+		// The chunks are not referenced by a manifest, so CollectGarbage will remove any that were written.
+		return nil, err
+	}
+
+	previous, etag, err := b.manifest(withoutDataStoreCache(ctx), key)
+	if err != nil && !errors.Is(err, ErrBlobNotFound) {
+		return nil, err
+	}
+
+	var value any = manifest
+	var resp *Response
+	if previous == nil {
+		_, resp, err = b.service.CreateDataStoreEntry(ctx, b.universeId, b.dataStoreId, b.scope, DataStoreEntryCreate{
+			Value: &value,
+		}, &DataStoreEntryCreateOptions{ID: Pointer(key)})
+	} else {
+		_, resp, err = b.service.UpdateDataStoreEntry(ctx, b.universeId, b.dataStoreId, b.scope, key, DataStoreEntryUpdate{
+			Etag:  Pointer(etag),
+			Value: &value,
+		}, nil)
+	}
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The previous chunks are no longer referenced. A Get that read the previous manifest and misses one of its chunks
+	// reads the manifest again. Any that fail to delete are removed by CollectGarbage.
+	if previous != nil {
+		_ = b.deleteChunks(ctx, key, previous)
+	}
+
+	return manifest, nil
+}
+
+// blobReadAttempts is how often Get reads the manifest again, when the chunks of the manifest it read were replaced by a Put.
+const blobReadAttempts = 3
+
+// Get will read the data stored under the key, verifying the hash of every chunk and of the data.
+//
+// ErrBlobNotFound is returned if the key does not have a blob.
+func (b *BlobStore) Get(ctx context.Context, key string) ([]byte, *BlobManifest, error) {
+	manifest, _, err := b.manifest(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		data, err := b.read(ctx, key, manifest)
+		if !isStatus(err, http.StatusNotFound) || attempt == blobReadAttempts {
+			return data, manifest, err
+		}
+
+		// A missing chunk means a Put replaced the blob after the manifest was read, which is only certain if the generation changed.
+		current, _, manifestErr := b.manifest(withoutDataStoreCache(ctx), key)
+		if manifestErr != nil {
+			return nil, manifest, manifestErr
+		}
+		if current.Generation == manifest.Generation {
+			return nil, manifest, err
+		}
+		manifest = current
+	}
+}
+
+// read will read and verify the chunks of a manifest.
+func (b *BlobStore) read(ctx context.Context, key string, manifest *BlobManifest) ([]byte, error) {
+	chunks := make([][]byte, manifest.ChunkCount)
+	err := b.forEachChunk(ctx, manifest.ChunkCount, func(ctx context.Context, index int) error {
+		entry, resp, err := b.service.GetDataStoreEntry(ctx, b.universeId, b.dataStoreId, b.scope, blobChunkKey(key, manifest.Generation, index))
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", index, err)
+		}
+
+		encoded, ok := entry.Value.(string)
+		if !ok {
+			return fmt.Errorf("chunk %d: value is not a string", index)
+		}
+
+		chunk, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", index, err)
+		}
+		if sha256Hex(chunk) != manifest.ChunkHashes[index] {
+			return fmt.Errorf("chunk %d: %w", index, ErrBlobHashMismatch)
+		}
+
+		chunks[index] = chunk
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(bytes.Join(chunks, nil)))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != manifest.Size || sha256Hex(data) != manifest.SHA256 {
+		return nil, ErrBlobHashMismatch
+	}
+
+	return data, nil
+}
+
+// Delete will remove the blob stored under the key. The manifest is removed first, so readers never see a blob with missing chunks.
+func (b *BlobStore) Delete(ctx context.Context, key string) error {
+	manifest, _, err := b.manifest(withoutDataStoreCache(ctx), key)
+	if errors.Is(err, ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.service.DeleteDataStoreEntry(ctx, b.universeId, b.dataStoreId, b.scope, key)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return err
+	}
+
+	return b.deleteChunks(ctx, key, manifest)
+}
+
+func (b *BlobStore) deleteChunks(ctx context.Context, key string, manifest *BlobManifest) error {
+	return b.forEachChunk(ctx, manifest.ChunkCount, func(ctx context.Context, index int) error {
+		return b.deleteChunk(ctx, blobChunkKey(key, manifest.Generation, index))
+	})
+}
+
+func (b *BlobStore) deleteChunk(ctx context.Context, chunkKey string) error {
+	resp, err := b.service.DeleteDataStoreEntry(ctx, b.universeId, b.dataStoreId, b.scope, chunkKey)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isStatus(err, http.StatusNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+type BlobGarbageCollectionReport struct {
+	Scanned int
+	Deleted []string
+	// Protected are orphaned chunks that were kept because they are younger than the grace period.
+	Protected int
+	Failed    map[string]error
+}
+
+// CollectGarbage will delete chunks that are not referenced by the manifest of their blob,
+// which are left behind by interrupted writes and failed cleanups.
+func (b *BlobStore) CollectGarbage(ctx context.Context) (*BlobGarbageCollectionReport, error) {
+	report := &BlobGarbageCollectionReport{Failed: make(map[string]error)}
+	generations := make(map[string]string)
+	cutoff := time.Now().Add(-b.GarbageCollectionGrace)
+
+	err := b.service.forEachDataStoreEntry(ctx, b.universeId, b.dataStoreId, b.scope, nil, func(entry DataStoreEntry) error {
+		match := blobChunkKeyRegex.FindStringSubmatch(entry.ID)
+		if match == nil {
+			return nil
+		}
+		report.Scanned++
+
+		key, generation := match[1], match[2]
+
+		current, ok := generations[key]
+		if !ok {
+			manifest, _, err := b.manifest(withoutDataStoreCache(ctx), key)
+			switch {
+			case errors.Is(err, ErrBlobNotFound):
+			case err != nil:
+				return err
+			default:
+				current = manifest.Generation
+			}
+			generations[key] = current
+		}
+
+		if generation == current {
+			return nil
+		}
+
+		created, err := strconv.ParseInt(match[3], 36, 64)
+		if err != nil || time.Unix(created, 0).After(cutoff) {
+			report.Protected++
+			return nil
+		}
+
+		if err := b.deleteChunk(ctx, entry.ID); err != nil {
+			report.Failed[entry.ID] = err
+			return nil
+		}
+
+		report.Deleted = append(report.Deleted, entry.ID)
+		return nil
+	})
+
+	return report, err
+}
@@ -1,5 +1,7 @@
 package opencloud
 
+import "encoding/json"
+
 // --- Response Structures
 
 type OperationResponse any
@@ -32,3 +34,15 @@ type OptionsWithFilter struct {
 func Pointer[T any](v T) *T {
 	return &v
 }
+
+/// --- Values
+
+// decodeValue will convert a decoded JSON value, such as the value of an entry, into v.
+func decodeValue(value any, v any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,281 @@
+package opencloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// ConfigBindError is returned when a config entry can not be bound to the field it is tagged on.
+type ConfigBindError struct {
+	Key   string
+	Field string
+	Value any
+	Err   error
+}
+
+func (e *ConfigBindError) Error() string {
+	return fmt.Sprintf("config key %q (field %s): %s", e.Key, e.Field, e.Err)
+}
+
+func (e *ConfigBindError) Unwrap() error {
+	return e.Err
+}
+
+// configField is a struct field tagged with `config:"key"`.
+//
+// The tag can be followed by ",required" to fail when the key is missing, and a `default:"value"` tag is used when
+// the key is missing. Defaults of string fields are used as is, other defaults are decoded as JSON.
+type configField struct {
+	key        string
+	name       string
+	value      reflect.Value
+	required   bool
+	defaultVal *string
+}
+
+func configFields(v reflect.Value) []configField {
+	var fields []configField
+
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, tagged := field.Tag.Lookup("config")
+
+		if field.Anonymous && !tagged && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, configFields(v.Field(i))...)
+			continue
+		}
+		if !tagged || tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		f := configField{key: name, name: field.Name, value: v.Field(i), required: options == "required"}
+		if def, ok := field.Tag.Lookup("default"); ok {
+			f.defaultVal = &def
+		}
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+func configStruct(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return rv, fmt.Errorf("config must be a struct or a pointer to a struct, got %T", v)
+	}
+
+	return rv, nil
+}
+
+// BindConfigEntries will set the fields of the struct v points to from config entry values.
+// Every field that can not be bound is reported as a ConfigBindError.
+func BindConfigEntries(entries map[string]any, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("config must be a non-nil pointer to a struct, got %T", v)
+	}
+	rv, err := configStruct(v)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, field := range configFields(rv) {
+		var err error
+		value, ok := entries[field.key]
+		if !ok {
+			switch {
+			case field.defaultVal != nil:
+				err = setConfigDefault(field.value, *field.defaultVal)
+			case field.required:
+				err = errors.New("missing required key")
+			}
+		} else {
+			err = setConfigValue(field.value, value)
+		}
+
+		if err != nil {
+			errs = append(errs, &ConfigBindError{Key: field.key, Field: field.name, Value: value, Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func setConfigDefault(field reflect.Value, def string) error {
+	target := field
+	for target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	if target.Kind() == reflect.String {
+		target.SetString(def)
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(def), target.Addr().Interface()); err != nil {
+		return fmt.Errorf("invalid default %q: %w", def, err)
+	}
+
+	return nil
+}
+
+// setConfigValue will set the field from a decoded JSON value, only converting between compatible types.
+func setConfigValue(field reflect.Value, value any) error {
+	if field.Kind() == reflect.Pointer {
+		if value == nil {
+			field.SetZero()
+			return nil
+		}
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setConfigValue(field.Elem(), value)
+	}
+
+	mismatch := fmt.Errorf("cannot bind %T to %s", value, field.Type())
+
+	switch field.Kind() {
+	case reflect.Interface:
+		if value == nil {
+			field.SetZero()
+			return nil
+		}
+		if !reflect.TypeOf(value).AssignableTo(field.Type()) {
+			return mismatch
+		}
+		field.Set(reflect.ValueOf(value))
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return mismatch
+		}
+		field.SetString(s)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(float64)
+		if !ok {
+			return mismatch
+		}
+		// The range is checked before converting, since converting a float that is out of range is implementation-defined.
+		limit := math.Ldexp(1, field.Type().Bits()-1)
+		if n != math.Trunc(n) || n < -limit || n >= limit {
+			return fmt.Errorf("%v does not fit in %s", n, field.Type())
+		}
+		field.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(float64)
+		if !ok {
+			return mismatch
+		}
+		if n != math.Trunc(n) || n < 0 || n >= math.Ldexp(1, field.Type().Bits()) {
+			return fmt.Errorf("%v does not fit in %s", n, field.Type())
+		}
+		field.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := value.(float64)
+		if !ok {
+			return mismatch
+		}
+		if field.OverflowFloat(n) {
+			return fmt.Errorf("%v does not fit in %s", n, field.Type())
+		}
+		field.SetFloat(n)
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		// JSON values can also be stored as a string, which is decoded as is.
+		var b []byte
+		switch value := value.(type) {
+		case map[string]any, []any:
+			var err error
+			if b, err = json.Marshal(value); err != nil {
+				return err
+			}
+		case string:
+			b = []byte(value)
+		default:
+			return mismatch
+		}
+
+		decoded := reflect.New(field.Type())
+		if err := json.Unmarshal(b, decoded.Interface()); err != nil {
+			return err
+		}
+		field.Set(decoded.Elem())
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
+
+// Bind will fetch the published config and set the fields of the struct v points to, using `config:"key"` tags.
+//
+// Fields can set a `default:"value"` tag for when the key is missing, or add ",required" to the config tag to fail instead.
+// Values are only converted between compatible types, a number is never bound to a string field or the other way around.
+// JSON objects and arrays are decoded into struct, map and slice fields.
+func (s *ConfigService) Bind(ctx context.Context, universeId, repository string, v any) error {
+	config, resp, err := s.GetConfig(ctx, universeId, repository)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]any, len(config.Entries))
+	for key, entry := range config.Entries {
+		entries[key] = entry.Value
+	}
+
+	return BindConfigEntries(entries, v)
+}
+
+// Draft will create a draft update from the fields of a struct with `config:"key"` tags, which is the reverse of Bind.
+// Nil pointer fields are left out, so a partial update does not change them.
+//
+// Values are encoded as JSON strings, the same way GetConfigDraft returns the values of draft entries.
+// The draft hash of the current draft has to be set before updating it.
+func (s *ConfigService) Draft(v any) (ConfigDraftUpdate, error) {
+	draft := ConfigDraftUpdate{Entries: make(map[string]any)}
+
+	rv, err := configStruct(v)
+	if err != nil {
+		return draft, err
+	}
+
+	for _, field := range configFields(rv) {
+		if field.value.Kind() == reflect.Pointer && field.value.IsNil() {
+			continue
+		}
+
+		b, err := json.Marshal(field.value.Interface())
+		if err != nil {
+			return draft, &ConfigBindError{Key: field.key, Field: field.name, Err: err}
+		}
+		draft.Entries[field.key] = string(b)
+	}
+
+	return draft, nil
+}
@@ -0,0 +1,209 @@
+package opencloud
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ConfigEntryChange is a key that was added, changed or removed between two versions of a config.
+type ConfigEntryChange struct {
+	Key string
+	// Old is the previous value, or nil if the key was added.
+	Old any
+	// New is the current value, or nil if the key was removed.
+	New any
+}
+
+// ConfigChangeEvent is delivered when a new version of a config was published.
+type ConfigChangeEvent struct {
+	PreviousVersion int
+	Version         int
+	Added           []ConfigEntryChange
+	Changed         []ConfigEntryChange
+	Removed         []ConfigEntryChange
+	// Config is the newly published config.
+	Config *FullConfig
+}
+
+// ConfigWatcher will keep a snapshot of the published config up to date, and deliver an event when a new version is published.
+type ConfigWatcher struct {
+	service    *ConfigService
+	universeId string
+	repository string
+
+	changes chan ConfigChangeEvent
+	// pendingBase is the config the event waiting on changes was compared against. It is only used by the polling goroutine.
+	pendingBase *FullConfig
+
+	mu     sync.RWMutex
+	config *FullConfig
+	err    error
+}
+
+// Watch will fetch the published config, then poll it and deliver an event on Changes whenever the config version changes.
+// The interval is backed off while the API responds with 429 Too Many Requests, and recovers once polls succeed again.
+//
+// Polling never waits for events to be received, so the snapshot stays current even if Changes is not read.
+// Polling stops and Changes is closed once the context is cancelled.
+func (s *ConfigService) Watch(ctx context.Context, universeId, repository string, interval time.Duration) (*ConfigWatcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("Watch: interval must be greater than 0, got %s", interval)
+	}
+
+	config, resp, err := s.GetConfig(ctx, universeId, repository)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ConfigWatcher{
+		service:    s,
+		universeId: universeId,
+		repository: repository,
+		changes:    make(chan ConfigChangeEvent, 1),
+		config:     config,
+	}
+	go w.watch(ctx, interval)
+
+	return w, nil
+}
+
+// Changes will deliver an event when a new version of the config is published. Only the latest event is kept while it is not received,
+// and it then covers every change since the last event that was received.
+func (w *ConfigWatcher) Changes() <-chan ConfigChangeEvent {
+	return w.changes
+}
+
+// Snapshot will return the latest config. It is shared between callers and must not be modified.
+func (w *ConfigWatcher) Snapshot() *FullConfig {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.config
+}
+
+// Version will return the version of the latest config.
+func (w *ConfigWatcher) Version() int {
+	return w.Snapshot().Metadata.ConfigVersion
+}
+
+// Value will return the value of a key in the latest config.
+func (w *ConfigWatcher) Value(key string) (any, bool) {
+	entry, ok := w.Snapshot().Entries[key]
+	return entry.Value, ok
+}
+
+// Bind will set the fields of the struct v points to from the latest config, the same way ConfigService.Bind does.
+func (w *ConfigWatcher) Bind(v any) error {
+	config := w.Snapshot()
+
+	entries := make(map[string]any, len(config.Entries))
+	for key, entry := range config.Entries {
+		entries[key] = entry.Value
+	}
+
+	return BindConfigEntries(entries, v)
+}
+
+// Err will return the error of the last poll, or nil if it succeeded. The snapshot is kept while polls fail.
+func (w *ConfigWatcher) Err() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.err
+}
+
+func (w *ConfigWatcher) watch(ctx context.Context, interval time.Duration) {
+	defer close(w.changes)
+
+	maxInterval := 16 * interval
+	current := interval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(current):
+		}
+
+		config, resp, err := w.service.GetConfig(ctx, w.universeId, w.repository)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if isStatus(err, http.StatusTooManyRequests) {
+			var respErr *ResponseError
+			errors.As(err, &respErr)
+			current = min(max(retryAfter(respErr.Response), current*2), maxInterval)
+		} else {
+			current = max(current/2, interval)
+		}
+
+		w.mu.Lock()
+		w.err = err
+		previous := w.config
+		if err == nil {
+			w.config = config
+		}
+		w.mu.Unlock()
+
+		if err != nil || config.Metadata.ConfigVersion == previous.Metadata.ConfigVersion {
+			continue
+		}
+
+		// An event that was not received yet is replaced, comparing against the config that event was compared against.
+		base := previous
+		select {
+		case <-w.changes:
+			base = w.pendingBase
+		default:
+		}
+
+		// The watcher is the only sender and the channel was just emptied, so this never blocks.
+		w.pendingBase = base
+		w.changes <- diffConfig(base, config)
+	}
+}
+
+// diffConfig will compare the entry values of two configs, with the keys of every change sorted.
+func diffConfig(previous, config *FullConfig) ConfigChangeEvent {
+	event := ConfigChangeEvent{
+		PreviousVersion: previous.Metadata.ConfigVersion,
+		Version:         config.Metadata.ConfigVersion,
+		Config:          config,
+	}
+
+	for key, entry := range config.Entries {
+		old, ok := previous.Entries[key]
+		switch {
+		case !ok:
+			event.Added = append(event.Added, ConfigEntryChange{Key: key, New: entry.Value})
+		case !reflect.DeepEqual(old.Value, entry.Value):
+			event.Changed = append(event.Changed, ConfigEntryChange{Key: key, Old: old.Value, New: entry.Value})
+		}
+	}
+	for key, entry := range previous.Entries {
+		if _, ok := config.Entries[key]; !ok {
+			event.Removed = append(event.Removed, ConfigEntryChange{Key: key, Old: entry.Value})
+		}
+	}
+
+	for _, changes := range [][]ConfigEntryChange{event.Added, event.Changed, event.Removed} {
+		slices.SortFunc(changes, func(a, b ConfigEntryChange) int {
+			return cmp.Compare(a.Key, b.Key)
+		})
+	}
+
+	return event
+}
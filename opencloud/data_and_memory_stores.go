@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/typical-developers/goblox/opencloud/filter"
 )
 
 // DataAndMemoryStoreService will handle communciation with the actions related to the API.
@@ -11,10 +15,20 @@ import (
 // Roblox Open Cloud API Docs: https://create.roblox.com/docs/en-us/cloud
 type DataAndMemoryStoreService service
 
+type DataStoreState string
+
+const (
+	DataStoreStateUnspecified DataStoreState = "STATE_UNSPECIFIED"
+	DataStoreStateActive      DataStoreState = "ACTIVE"
+	DataStoreStateDeleted     DataStoreState = "DELETED"
+)
+
 type DataStore struct {
-	Path       string `json:"path"`
-	CreateTime string `json:"createTime"`
-	ID         string `json:"id"`
+	Path       string         `json:"path"`
+	CreateTime string         `json:"createTime"`
+	ID         string         `json:"id"`
+	State      DataStoreState `json:"state"`
+	ExpireTime string         `json:"expireTime"`
 }
 
 type DataStoreList struct {
@@ -51,6 +65,114 @@ func (s *DataAndMemoryStoreService) ListDataStores(ctx context.Context, universe
 	return dataStoreList, resp, nil
 }
 
+type dataStoreGetOptions struct {
+	MaxPageSize *int    `url:"maxPageSize,omitempty"`
+	PageToken   *string `url:"pageToken,omitempty"`
+	Filter      *string `url:"filter,omitempty"`
+	ShowDeleted *bool   `url:"showDeleted,omitempty"`
+}
+
+// GetDataStore will fetch a specific data store for a specific universe, including data stores that are scheduled for deletion.
+// Roblox does not have an endpoint for a single data store, so the data stores are listed with a filter on the ID.
+//
+// A nil data store is returned if it does not exist.
+//
+// Required scopes: universe-datastores.control:list
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#Cloud_ListDataStores
+//
+// [GET] /cloud/v2/universes/{universe_id}/data-stores
+func (s *DataAndMemoryStoreService) GetDataStore(ctx context.Context, universeId, dataStoreId string) (*DataStore, *Response, error) {
+	idFilter, err := filter.DataStoreEntries().IDStartsWith(dataStoreId).Build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts := &dataStoreGetOptions{
+		MaxPageSize: Pointer(100),
+		Filter:      Pointer(idFilter),
+		ShowDeleted: Pointer(true),
+	}
+
+	for {
+		u, err := addOpts(fmt.Sprintf("/cloud/v2/universes/%s/data-stores", universeId), opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		req, err := s.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dataStoreList := new(DataStoreList)
+		resp, err := s.client.Do(ctx, req, dataStoreList)
+		if err != nil {
+			return nil, resp, err
+		}
+
+		for _, dataStore := range dataStoreList.DataStores {
+			if dataStore.ID == dataStoreId {
+				return &dataStore, resp, nil
+			}
+		}
+
+		if dataStoreList.NextPageToken == "" {
+			return nil, resp, nil
+		}
+		opts.PageToken = Pointer(dataStoreList.NextPageToken)
+	}
+}
+
+// DeleteDataStore will schedule a data store for a specific universe to be permanently deleted after 30 days.
+// It can be restored with UndeleteDataStore until it expires.
+//
+// Required scopes: universe-datastores.control:delete
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#Cloud_DeleteDataStore
+//
+// [DELETE] /cloud/v2/universes/{universe_id}/data-stores/{data_store_id}
+func (s *DataAndMemoryStoreService) DeleteDataStore(ctx context.Context, universeId, dataStoreId string) (*DataStore, *Response, error) {
+	u := fmt.Sprintf("/cloud/v2/universes/%s/data-stores/%s", universeId, dataStoreId)
+
+	req, err := s.client.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataStore := new(DataStore)
+	resp, err := s.client.Do(ctx, req, dataStore)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return dataStore, resp, nil
+}
+
+// UndeleteDataStore will restore a data store for a specific universe that is scheduled for deletion.
+//
+// Required scopes: universe-datastores.control:create
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#Cloud_UndeleteDataStore
+//
+// [POST] /cloud/v2/universes/{universe_id}/data-stores/{data_store_id}:undelete
+func (s *DataAndMemoryStoreService) UndeleteDataStore(ctx context.Context, universeId, dataStoreId string) (*DataStore, *Response, error) {
+	u := fmt.Sprintf("/cloud/v2/universes/%s/data-stores/%s:undelete", universeId, dataStoreId)
+
+	req, err := s.client.NewRequest(http.MethodPost, u, struct{}{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataStore := new(DataStore)
+	resp, err := s.client.Do(ctx, req, dataStore)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return dataStore, resp, nil
+}
+
 type DataStoreSnapshot struct {
 	NewSnapshotTaken   bool   `json:"newSnapshotTaken"`
 	LatestSnapshotTime string `json:"latestSnapshotTime"`
@@ -99,6 +221,8 @@ type DataStoreEntry struct {
 	Value                any                 `json:"value"`
 	ID                   string              `json:"id"`
 	User                 string              `json:"user"`
+	Users                []string            `json:"users"`
+	Attributes           map[string]any      `json:"attributes"`
 }
 
 type DataStoreEntriesList struct {
@@ -175,6 +299,12 @@ func (s *DataAndMemoryStoreService) CreateDataStoreEntry(ctx context.Context, un
 	}
 	u += "/entries"
 
+	if opts != nil && opts.ID != nil {
+		if err := s.client.validateDataStoreEntryPath(dataStoreId, scope, *opts.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	u, err := addOpts(u, opts)
 	if err != nil {
 		return nil, nil, err
@@ -191,6 +321,9 @@ func (s *DataAndMemoryStoreService) CreateDataStoreEntry(ctx context.Context, un
 		return nil, resp, err
 	}
 
+	if opts != nil && opts.ID != nil {
+		s.invalidateDataStoreEntry(resp, universeId, dataStoreId, scope, *opts.ID)
+	}
 	return dataStoreEntry, resp, nil
 }
 
@@ -210,6 +343,19 @@ func (s *DataAndMemoryStoreService) GetDataStoreEntry(ctx context.Context, unive
 	}
 	u += fmt.Sprintf("/entries/%s", entryId)
 
+	// A revision is read with an entry ID of "{entryId}@{revisionId}", so only the entry ID is validated.
+	key := entryId
+	if i := strings.LastIndex(entryId, "@"); i >= 0 {
+		key = entryId[:i]
+	}
+	if err := s.client.validateDataStoreEntryPath(datastoreId, scope, key); err != nil {
+		return nil, nil, err
+	}
+
+	if cache := s.client.dataStoreCache; cache.cacheable(ctx, entryId) {
+		return cache.get(ctx, s.client, u, universeId, datastoreId, scope, entryId)
+	}
+
 	req, err := s.client.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
@@ -240,6 +386,10 @@ func (s *DataAndMemoryStoreService) DeleteDataStoreEntry(ctx context.Context, un
 	}
 	u += fmt.Sprintf("/entries/%s", entryId)
 
+	if err := s.client.validateDataStoreEntryPath(datastoreId, scope, entryId); err != nil {
+		return nil, err
+	}
+
 	req, err := s.client.NewRequest(http.MethodDelete, u, nil)
 	if err != nil {
 		return nil, err
@@ -250,6 +400,7 @@ func (s *DataAndMemoryStoreService) DeleteDataStoreEntry(ctx context.Context, un
 		return resp, err
 	}
 
+	s.invalidateDataStoreEntry(resp, universeId, datastoreId, scope, entryId)
 	return resp, nil
 }
 
@@ -280,6 +431,10 @@ func (s *DataAndMemoryStoreService) UpdateDataStoreEntry(ctx context.Context, un
 	}
 	u += fmt.Sprintf("/entries/%s", entryId)
 
+	if err := s.client.validateDataStoreEntryPath(datastoreId, scope, entryId); err != nil {
+		return nil, nil, err
+	}
+
 	u, err := addOpts(u, opts)
 	if err != nil {
 		return nil, nil, err
@@ -296,6 +451,7 @@ func (s *DataAndMemoryStoreService) UpdateDataStoreEntry(ctx context.Context, un
 		return nil, resp, err
 	}
 
+	s.invalidateDataStoreEntry(resp, universeId, datastoreId, scope, entryId)
 	return dataStoreEntry, resp, nil
 }
 
@@ -321,6 +477,10 @@ func (s *DataAndMemoryStoreService) IncrementDataStoreEntry(ctx context.Context,
 	}
 	u += fmt.Sprintf("/entries/%s:increment", entryId)
 
+	if err := s.client.validateDataStoreEntryPath(datastoreId, scope, entryId); err != nil {
+		return nil, nil, err
+	}
+
 	req, err := s.client.NewRequest(http.MethodPost, u, data)
 	if err != nil {
 		return nil, nil, err
@@ -332,9 +492,18 @@ func (s *DataAndMemoryStoreService) IncrementDataStoreEntry(ctx context.Context,
 		return nil, resp, err
 	}
 
+	s.invalidateDataStoreEntry(resp, universeId, datastoreId, scope, entryId)
 	return dataStoreEntry, resp, nil
 }
 
+// invalidateDataStoreEntry will remove a written entry from the data store cache, if it is enabled.
+// Entries are also removed when the write failed on a precondition or conflict, since the cached etag is stale.
+func (s *DataAndMemoryStoreService) invalidateDataStoreEntry(resp *Response, universeId, datastoreId string, scope *string, entryId string) {
+	if err := checkResponse(resp); s.client.dataStoreCache != nil && (err == nil || isConflict(err)) {
+		s.client.dataStoreCache.Invalidate(universeId, datastoreId, scope, entryId)
+	}
+}
+
 type DataStoryEntryRevisionsList struct {
 	DataStoreEntries []DataStoreEntry `json:"dataStoreEntries"`
 	NextPageToken    string           `json:"nextPageToken"`
@@ -356,6 +525,10 @@ func (s *DataAndMemoryStoreService) ListDataStoreEntryRevisions(ctx context.Cont
 	}
 	u += fmt.Sprintf("/entries/%s:listRevisions", entryId)
 
+	if err := s.client.validateDataStoreEntryPath(datastoreId, scope, entryId); err != nil {
+		return nil, nil, err
+	}
+
 	u, err := addOpts(u, opts)
 	if err != nil {
 		return nil, nil, err
@@ -399,6 +572,11 @@ func (s *DataAndMemoryStoreService) FlushMemoryStore(ctx context.Context, univer
 	return operation, resp, nil
 }
 
+// memoryStoreTTL will format a duration as a memory store TTL or invisibility window, which is at least one second.
+func memoryStoreTTL(d time.Duration) *string {
+	return Pointer(fmt.Sprintf("%ds", max(int(d.Seconds()), 1)))
+}
+
 type MemoryStoreQueueItem struct {
 	Path       string `json:"path"`
 	Data       any    `json:"data"`
@@ -505,12 +683,13 @@ func (s *DataAndMemoryStoreService) ReadMemoryStoreQueueItems(ctx context.Contex
 }
 
 type MemoryStoreSortedMapItem struct {
-	Path           string  `json:"path"`
-	Value          any     `json:"value"`
-	Etag           string  `json:"etag"`
-	ID             string  `json:"id"`
-	StringSortKey  *string `json:"stringSortKey,omitempty"`
-	NumericSortKey *int    `json:"numericSortKey,omitempty"`
+	Path           string   `json:"path"`
+	Value          any      `json:"value"`
+	Etag           string   `json:"etag"`
+	ID             string   `json:"id"`
+	StringSortKey  *string  `json:"stringSortKey,omitempty"`
+	NumericSortKey *float64 `json:"numericSortKey,omitempty"`
+	ExpireTime     string   `json:"expireTime,omitempty"`
 }
 
 type MemoryStoreSortedMapList struct {
@@ -555,10 +734,11 @@ func (s *DataAndMemoryStoreService) ListMemoryStoreSortedMapItems(ctx context.Co
 }
 
 type MemoryStoreSortedMapItemCreate struct {
-	Value         *any    `json:"value,omitempty"`
-	TTL           *string `json:"ttl,omitempty"`
-	ID            *string `json:"id,omitempty"`
-	StringSortKey *string `json:"stringSortKey,omitempty"`
+	Value          *any     `json:"value,omitempty"`
+	TTL            *string  `json:"ttl,omitempty"`
+	ID             *string  `json:"id,omitempty"`
+	StringSortKey  *string  `json:"stringSortKey,omitempty"`
+	NumericSortKey *float64 `json:"numericSortKey,omitempty"`
 }
 
 type MemoryStoreSortedMapItemCreateOptions struct {
@@ -642,14 +822,17 @@ func (s *DataAndMemoryStoreService) DeleteMemoryStoreSortedMapItem(ctx context.C
 }
 
 type MemoryStoreSortedMapItemUpdate struct {
-	Value         *any    `json:"value,omitempty"`
-	TTL           *string `json:"ttl,omitempty"`
-	ID            *string `json:"id,omitempty"`
-	StringSortKey *string `json:"stringSortKey,omitempty"`
+	Etag           *string  `json:"etag,omitempty"`
+	Value          *any     `json:"value,omitempty"`
+	TTL            *string  `json:"ttl,omitempty"`
+	ID             *string  `json:"id,omitempty"`
+	StringSortKey  *string  `json:"stringSortKey,omitempty"`
+	NumericSortKey *float64 `json:"numericSortKey,omitempty"`
 }
 
 type MemoryStoreSortedMapItemUpdateOpts struct {
-	ID string `url:"id,omitempty"`
+	ID           string `url:"id,omitempty"`
+	AllowMissing *bool  `url:"allowMissing,omitempty"`
 }
 
 // UpdateMemoryStoreSortedMapItem will update a specific item in the memory store sorted map for a specific universe.
@@ -681,6 +864,180 @@ func (s *DataAndMemoryStoreService) UpdateMemoryStoreSortedMapItem(ctx context.C
 	return memoryStoreSortedMapItem, resp, nil
 }
 
+type MemoryStoreHashMapItem struct {
+	Path       string `json:"path"`
+	Value      any    `json:"value"`
+	Etag       string `json:"etag"`
+	TTL        string `json:"ttl,omitempty"`
+	ExpireTime string `json:"expireTime,omitempty"`
+	ID         string `json:"id"`
+}
+
+type MemoryStoreHashMapList struct {
+	MemoryStoreHashMapItems []MemoryStoreHashMapItem `json:"memoryStoreHashMapItems"`
+	NextPageToken           string                   `json:"nextPageToken"`
+}
+
+type MemoryStoreHashMapItemListOptions struct {
+	MaxPageSize *int    `url:"maxPageSize,omitempty"`
+	PageToken   *string `url:"pageToken,omitempty"`
+}
+
+// ListMemoryStoreHashMapItems will fetch a list of memory store hash map items for a specific hash map under a specific universe.
+//
+// Required scopes: universe.memory-store.hash-map:read
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/MemoryStoreHashMapItem#Cloud_ListMemoryStoreHashMapItems
+//
+// [GET] /cloud/v2/universes/{universe_id}/memory-store/hash-maps/{hash_map_id}/items
+func (s *DataAndMemoryStoreService) ListMemoryStoreHashMapItems(ctx context.Context, universeId, hashMapId string, opts *MemoryStoreHashMapItemListOptions) (*MemoryStoreHashMapList, *Response, error) {
+	u := fmt.Sprintf("/cloud/v2/universes/%s/memory-store/hash-maps/%s/items", universeId, hashMapId)
+
+	u, err := addOpts(u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	memoryStoreHashMapList := new(MemoryStoreHashMapList)
+	resp, err := s.client.Do(ctx, req, memoryStoreHashMapList)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return memoryStoreHashMapList, resp, nil
+}
+
+type MemoryStoreHashMapItemCreate struct {
+	Value *any    `json:"value,omitempty"`
+	TTL   *string `json:"ttl,omitempty"`
+	ID    *string `json:"id,omitempty"`
+}
+
+type MemoryStoreHashMapItemCreateOptions struct {
+	ID string `url:"id,omitempty"`
+}
+
+// CreateMemoryStoreHashMapItem will create a new item in the memory store hash map for a specific universe.
+//
+// Required scopes: universe.memory-store.hash-map:write
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/MemoryStoreHashMapItem#Cloud_CreateMemoryStoreHashMapItem
+//
+// [POST] /cloud/v2/universes/{universe_id}/memory-store/hash-maps/{hash_map_id}/items
+func (s *DataAndMemoryStoreService) CreateMemoryStoreHashMapItem(ctx context.Context, universeId, hashMapId string, data MemoryStoreHashMapItemCreate, opts *MemoryStoreHashMapItemCreateOptions) (*MemoryStoreHashMapItem, *Response, error) {
+	u := fmt.Sprintf("/cloud/v2/universes/%s/memory-store/hash-maps/%s/items", universeId, hashMapId)
+
+	u, err := addOpts(u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	memoryStoreHashMapItem := new(MemoryStoreHashMapItem)
+	resp, err := s.client.Do(ctx, req, memoryStoreHashMapItem)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return memoryStoreHashMapItem, resp, nil
+}
+
+// GetMemoryStoreHashMapItem will fetch a specific item in the memory store hash map for a specific universe.
+//
+// Required scopes: universe.memory-store.hash-map:read
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/MemoryStoreHashMapItem#Cloud_GetMemoryStoreHashMapItem
+//
+// [GET] /cloud/v2/universes/{universe_id}/memory-store/hash-maps/{hash_map_id}/items/{item_id}
+func (s *DataAndMemoryStoreService) GetMemoryStoreHashMapItem(ctx context.Context, universeId, hashMapId, itemId string) (*MemoryStoreHashMapItem, *Response, error) {
+	u := fmt.Sprintf("/cloud/v2/universes/%s/memory-store/hash-maps/%s/items/%s", universeId, hashMapId, itemId)
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	memoryStoreHashMapItem := new(MemoryStoreHashMapItem)
+	resp, err := s.client.Do(ctx, req, memoryStoreHashMapItem)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return memoryStoreHashMapItem, resp, nil
+}
+
+// DeleteMemoryStoreHashMapItem will delete a specific item in the memory store hash map for a specific universe.
+//
+// Required scopes: universe.memory-store.hash-map:write
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/MemoryStoreHashMapItem#Cloud_DeleteMemoryStoreHashMapItem
+//
+// [DELETE] /cloud/v2/universes/{universe_id}/memory-store/hash-maps/{hash_map_id}/items/{item_id}
+func (s *DataAndMemoryStoreService) DeleteMemoryStoreHashMapItem(ctx context.Context, universeId, hashMapId, itemId string) (*Response, error) {
+	u := fmt.Sprintf("/cloud/v2/universes/%s/memory-store/hash-maps/%s/items/%s", universeId, hashMapId, itemId)
+
+	req, err := s.client.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+type MemoryStoreHashMapItemUpdate struct {
+	Etag  *string `json:"etag,omitempty"`
+	Value *any    `json:"value,omitempty"`
+	TTL   *string `json:"ttl,omitempty"`
+}
+
+type MemoryStoreHashMapItemUpdateOpts struct {
+	AllowMissing *bool `url:"allowMissing,omitempty"`
+}
+
+// UpdateMemoryStoreHashMapItem will update a specific item in the memory store hash map for a specific universe.
+// The update is rejected when an etag is set and the item was changed since it was read.
+//
+// Required scopes: universe.memory-store.hash-map:write
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/MemoryStoreHashMapItem#Cloud_UpdateMemoryStoreHashMapItem
+//
+// [PATCH] /cloud/v2/universes/{universe_id}/memory-store/hash-maps/{hash_map_id}/items/{item_id}
+func (s *DataAndMemoryStoreService) UpdateMemoryStoreHashMapItem(ctx context.Context, universeId, hashMapId, itemId string, data MemoryStoreHashMapItemUpdate, opts *MemoryStoreHashMapItemUpdateOpts) (*MemoryStoreHashMapItem, *Response, error) {
+	u := fmt.Sprintf("/cloud/v2/universes/%s/memory-store/hash-maps/%s/items/%s", universeId, hashMapId, itemId)
+
+	u, err := addOpts(u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodPatch, u, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	memoryStoreHashMapItem := new(MemoryStoreHashMapItem)
+	resp, err := s.client.Do(ctx, req, memoryStoreHashMapItem)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return memoryStoreHashMapItem, resp, nil
+}
+
 type OrderedDataStoreEntry struct {
 	Path  string `json:"path"`
 	Value int    `json:"value"`
@@ -0,0 +1,453 @@
+package opencloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var dataStoreKeyNumberRegex = regexp.MustCompile(`[0-9]+`)
+
+type DataStoreAnalysisOptions struct {
+	// Scopes to scan. Every scope that has entries is scanned when empty.
+	Scopes []string
+	// SampleSize is the maximum amount of entries fetched from each scope. Every key is still listed and counted,
+	// but sizes, staleness and the schema are only based on a random sample of the values. Every value is fetched when 0.
+	SampleSize int
+	// StaleAfter is the age of the latest revision after which an entry is reported as stale. Defaults to 90 days.
+	StaleAfter time.Duration
+	// Concurrency is the amount of entries fetched at the same time. Defaults to 8, and is at least 1.
+	Concurrency int
+}
+
+type DataStoreScopeUsage struct {
+	Scope      string `json:"scope"`
+	Keys       int    `json:"keys"`
+	Sampled    int    `json:"sampled"`
+	SampleSize int    `json:"sampleSize"`
+}
+
+// DataStoreKeyPatternUsage groups keys by their pattern, which is the key with every number replaced by "{n}".
+type DataStoreKeyPatternUsage struct {
+	Pattern string `json:"pattern"`
+	Keys    int    `json:"keys"`
+	// SampledSize is the total size of the sampled values with this pattern.
+	SampledSize int `json:"sampledSize"`
+}
+
+type DataStoreSizeBucket struct {
+	Label   string `json:"label"`
+	MaxSize int    `json:"maxSize"`
+	Count   int    `json:"count"`
+}
+
+// DataStoreValueSizes is the distribution of the JSON encoded size of the sampled values, in bytes.
+type DataStoreValueSizes struct {
+	Total   int                   `json:"total"`
+	Min     int                   `json:"min"`
+	Max     int                   `json:"max"`
+	Mean    int                   `json:"mean"`
+	P50     int                   `json:"p50"`
+	P95     int                   `json:"p95"`
+	Largest []DataStoreSizedKey   `json:"largest"`
+	Buckets []DataStoreSizeBucket `json:"buckets"`
+}
+
+type DataStoreSizedKey struct {
+	Scope string `json:"scope"`
+	ID    string `json:"id"`
+	Size  int    `json:"size"`
+}
+
+type DataStoreStaleKey struct {
+	Scope                string `json:"scope"`
+	ID                   string `json:"id"`
+	RevisionCreationTime string `json:"revisionCreationTime"`
+}
+
+type DataStoreStaleKeys struct {
+	After  string              `json:"after"`
+	Count  int                 `json:"count"`
+	Oldest []DataStoreStaleKey `json:"oldest"`
+}
+
+// DataStoreSchemaField is a path found in the sampled values, such as "$.inventory[].id".
+type DataStoreSchemaField struct {
+	Path string `json:"path"`
+	// Count is the amount of sampled values that contain the path.
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"`
+	// Types counts the sampled values by the JSON type found at the path.
+	Types map[string]int `json:"types"`
+	// Conflict is set when the path holds more than one type, ignoring null.
+	Conflict bool `json:"conflict"`
+}
+
+// DataStoreAnalysisReport describes what is stored in a data store, to plan migrations and find bloat.
+type DataStoreAnalysisReport struct {
+	Universe      string                     `json:"universe"`
+	DataStore     string                     `json:"dataStore"`
+	GeneratedTime string                     `json:"generatedTime"`
+	Keys          int                        `json:"keys"`
+	Sampled       int                        `json:"sampled"`
+	Failed        int                        `json:"failed"`
+	Scopes        []DataStoreScopeUsage      `json:"scopes"`
+	Patterns      []DataStoreKeyPatternUsage `json:"patterns"`
+	Sizes         DataStoreValueSizes        `json:"sizes"`
+	Stale         DataStoreStaleKeys         `json:"stale"`
+	Schema        []DataStoreSchemaField     `json:"schema"`
+}
+
+// dataStoreKeyPattern will replace every number in a key, so keys such as "Player_1" and "Player_2" are grouped together.
+func dataStoreKeyPattern(key string) string {
+	return dataStoreKeyNumberRegex.ReplaceAllString(key, "{n}")
+}
+
+// AnalyzeDataStore will scan the keys of a data store and sample its values, reporting key counts, value sizes,
+// stale entries and an inferred schema of the values.
+func (s *DataAndMemoryStoreService) AnalyzeDataStore(ctx context.Context, universeId, dataStoreId string, opts DataStoreAnalysisOptions) (*DataStoreAnalysisReport, error) {
+	staleAfter := opts.StaleAfter
+	if staleAfter == 0 {
+		staleAfter = 90 * 24 * time.Hour
+	}
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = 8
+	}
+	concurrency = max(concurrency, 1)
+
+	scopes := opts.Scopes
+	if len(scopes) == 0 {
+		var err error
+		if scopes, err = s.listDataStoreScopes(ctx, universeId, dataStoreId); err != nil {
+			return nil, err
+		}
+		if len(scopes) == 0 {
+			scopes = []string{"global"}
+		}
+	}
+
+	report := &DataStoreAnalysisReport{
+		Universe:      universeId,
+		DataStore:     dataStoreId,
+		GeneratedTime: time.Now().UTC().Format(time.RFC3339Nano),
+		Stale:         DataStoreStaleKeys{After: time.Now().Add(-staleAfter).UTC().Format(time.RFC3339Nano)},
+	}
+
+	patterns := make(map[string]*DataStoreKeyPatternUsage)
+	schema := newDataStoreSchema()
+	var sized []DataStoreSizedKey
+	var stale []DataStoreStaleKey
+
+	for _, scopeId := range scopes {
+		var scope *string
+		if scopeId != "global" {
+			scope = Pointer(scopeId)
+		}
+
+		usage := DataStoreScopeUsage{Scope: scopeId}
+		var sample []string
+
+		// Reservoir sampling keeps an even sample without knowing the amount of keys upfront.
+		err := s.forEachDataStoreEntry(ctx, universeId, dataStoreId, scope, nil, func(entry DataStoreEntry) error {
+			usage.Keys++
+
+			pattern := dataStoreKeyPattern(entry.ID)
+			if patterns[pattern] == nil {
+				patterns[pattern] = &DataStoreKeyPatternUsage{Pattern: pattern}
+			}
+			patterns[pattern].Keys++
+
+			switch {
+			case opts.SampleSize == 0 || len(sample) < opts.SampleSize:
+				sample = append(sample, entry.ID)
+			default:
+				if i := rand.IntN(usage.Keys); i < opts.SampleSize {
+					sample[i] = entry.ID
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+
+		entries := make([]*DataStoreEntry, len(sample))
+		var wg sync.WaitGroup
+		semaphore := make(chan struct{}, concurrency)
+		for i, id := range sample {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+
+				entry, resp, err := s.GetDataStoreEntry(ctx, universeId, dataStoreId, scope, id)
+				if err == nil {
+					err = checkResponse(resp)
+				}
+				if err == nil {
+					entries[i] = entry
+				}
+			}()
+		}
+		wg.Wait()
+
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		for _, entry := range entries {
+			if entry == nil {
+				report.Failed++
+				continue
+			}
+			usage.Sampled++
+
+			b, err := json.Marshal(entry.Value)
+			if err != nil {
+				return report, err
+			}
+			sized = append(sized, DataStoreSizedKey{Scope: scopeId, ID: entry.ID, Size: len(b)})
+			patterns[dataStoreKeyPattern(entry.ID)].SampledSize += len(b)
+
+			if written, err := time.Parse(time.RFC3339Nano, entry.RevisionCreationTime); err == nil && time.Since(written) > staleAfter {
+				stale = append(stale, DataStoreStaleKey{Scope: scopeId, ID: entry.ID, RevisionCreationTime: entry.RevisionCreationTime})
+			}
+
+			schema.add(entry.Value)
+		}
+
+		usage.SampleSize = len(sample)
+		report.Keys += usage.Keys
+		report.Sampled += usage.Sampled
+		report.Scopes = append(report.Scopes, usage)
+	}
+
+	for _, pattern := range patterns {
+		report.Patterns = append(report.Patterns, *pattern)
+	}
+	sort.Slice(report.Patterns, func(i, j int) bool {
+		if report.Patterns[i].Keys != report.Patterns[j].Keys {
+			return report.Patterns[i].Keys > report.Patterns[j].Keys
+		}
+		return report.Patterns[i].Pattern < report.Patterns[j].Pattern
+	})
+
+	report.Sizes = dataStoreValueSizes(sized)
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].RevisionCreationTime < stale[j].RevisionCreationTime })
+	report.Stale.Count = len(stale)
+	report.Stale.Oldest = stale[:min(len(stale), 20)]
+
+	report.Schema = schema.fields()
+
+	return report, nil
+}
+
+func dataStoreValueSizes(sized []DataStoreSizedKey) DataStoreValueSizes {
+	sizes := DataStoreValueSizes{
+		Buckets: []DataStoreSizeBucket{
+			{Label: "<= 1 KB", MaxSize: 1 << 10},
+			{Label: "<= 10 KB", MaxSize: 10 << 10},
+			{Label: "<= 100 KB", MaxSize: 100 << 10},
+			{Label: "<= 1 MB", MaxSize: 1 << 20},
+			{Label: "<= 4 MB", MaxSize: MaxDataStoreEntryValueSize},
+		},
+	}
+	if len(sized) == 0 {
+		return sizes
+	}
+
+	sized = slices.Clone(sized)
+	sort.Slice(sized, func(i, j int) bool { return sized[i].Size > sized[j].Size })
+
+	for _, key := range sized {
+		sizes.Total += key.Size
+		for i := range sizes.Buckets {
+			if key.Size <= sizes.Buckets[i].MaxSize || i == len(sizes.Buckets)-1 {
+				sizes.Buckets[i].Count++
+				break
+			}
+		}
+	}
+
+	percentile := func(p float64) int {
+		return sized[int(float64(len(sized)-1)*(1-p))].Size
+	}
+
+	sizes.Max = sized[0].Size
+	sizes.Min = sized[len(sized)-1].Size
+	sizes.Mean = sizes.Total / len(sized)
+	sizes.P50 = percentile(0.5)
+	sizes.P95 = percentile(0.95)
+	sizes.Largest = sized[:min(len(sized), 10)]
+
+	return sizes
+}
+
+type dataStoreSchema struct {
+	values int
+	paths  map[string]*DataStoreSchemaField
+}
+
+func newDataStoreSchema() *dataStoreSchema {
+	return &dataStoreSchema{paths: make(map[string]*DataStoreSchemaField)}
+}
+
+func jsonTypeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// add will record the paths and types of a value. Every path is counted at most once per value.
+func (d *dataStoreSchema) add(value any) {
+	d.values++
+
+	seen := make(map[string]map[string]bool)
+	var walk func(path string, value any)
+	walk = func(path string, value any) {
+		if seen[path] == nil {
+			seen[path] = make(map[string]bool)
+		}
+		seen[path][jsonTypeOf(value)] = true
+
+		switch value := value.(type) {
+		case []any:
+			for _, item := range value {
+				walk(path+"[]", item)
+			}
+		case map[string]any:
+			for key, item := range value {
+				// Objects keyed by IDs would add a path for every ID, so numeric keys are grouped together.
+				if dataStoreKeyPattern(key) == "{n}" {
+					key = "{n}"
+				}
+				walk(path+"."+key, item)
+			}
+		}
+	}
+	walk("$", value)
+
+	for path, types := range seen {
+		field := d.paths[path]
+		if field == nil {
+			field = &DataStoreSchemaField{Path: path, Types: make(map[string]int)}
+			d.paths[path] = field
+		}
+
+		field.Count++
+		for t := range types {
+			field.Types[t]++
+		}
+	}
+}
+
+func (d *dataStoreSchema) fields() []DataStoreSchemaField {
+	fields := make([]DataStoreSchemaField, 0, len(d.paths))
+	for _, field := range d.paths {
+		field.Frequency = float64(field.Count) / float64(d.values)
+
+		types := 0
+		for t := range field.Types {
+			if t != "null" {
+				types++
+			}
+		}
+		field.Conflict = types > 1
+
+		fields = append(fields, *field)
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	return fields
+}
+
+// JSON will encode the report as indented JSON.
+func (r *DataStoreAnalysisReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Markdown will render the report as a Markdown document.
+func (r *DataStoreAnalysisReport) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Data store analysis: %s\n\n", r.DataStore)
+	fmt.Fprintf(&b, "Universe %s, generated at %s.\n\n", r.Universe, r.GeneratedTime)
+	fmt.Fprintf(&b, "%d keys, %d values sampled, %d values failed to load.\n\n", r.Keys, r.Sampled, r.Failed)
+
+	b.WriteString("## Scopes\n\n| Scope | Keys | Sampled |\n| --- | ---: | ---: |\n")
+	for _, scope := range r.Scopes {
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", markdownCell(scope.Scope), scope.Keys, scope.Sampled)
+	}
+
+	b.WriteString("\n## Key patterns\n\n| Pattern | Keys | Sampled size |\n| --- | ---: | ---: |\n")
+	for _, pattern := range r.Patterns {
+		fmt.Fprintf(&b, "| `%s` | %d | %d |\n", markdownCell(pattern.Pattern), pattern.Keys, pattern.SampledSize)
+	}
+
+	b.WriteString("\n## Value sizes\n\n")
+	fmt.Fprintf(&b, "Total %d bytes, min %d, mean %d, p50 %d, p95 %d, max %d.\n\n", r.Sizes.Total, r.Sizes.Min, r.Sizes.Mean, r.Sizes.P50, r.Sizes.P95, r.Sizes.Max)
+	b.WriteString("| Size | Values |\n| --- | ---: |\n")
+	for _, bucket := range r.Sizes.Buckets {
+		fmt.Fprintf(&b, "| %s | %d |\n", bucket.Label, bucket.Count)
+	}
+	if len(r.Sizes.Largest) > 0 {
+		b.WriteString("\n| Largest key | Scope | Size |\n| --- | --- | ---: |\n")
+		for _, key := range r.Sizes.Largest {
+			fmt.Fprintf(&b, "| `%s` | %s | %d |\n", markdownCell(key.ID), markdownCell(key.Scope), key.Size)
+		}
+	}
+
+	b.WriteString("\n## Stale keys\n\n")
+	fmt.Fprintf(&b, "%d sampled values were last written before %s.\n", r.Stale.Count, r.Stale.After)
+	if len(r.Stale.Oldest) > 0 {
+		b.WriteString("\n| Key | Scope | Last written |\n| --- | --- | --- |\n")
+		for _, key := range r.Stale.Oldest {
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", markdownCell(key.ID), markdownCell(key.Scope), key.RevisionCreationTime)
+		}
+	}
+
+	b.WriteString("\n## Schema\n\n| Path | Frequency | Types | Conflict |\n| --- | ---: | --- | --- |\n")
+	for _, field := range r.Schema {
+		types := make([]string, 0, len(field.Types))
+		for t, count := range field.Types {
+			types = append(types, fmt.Sprintf("%s (%d)", t, count))
+		}
+		sort.Strings(types)
+
+		conflict := ""
+		if field.Conflict {
+			conflict = "yes"
+		}
+		fmt.Fprintf(&b, "| `%s` | %.1f%% | %s | %s |\n", markdownCell(field.Path), field.Frequency*100, strings.Join(types, ", "), conflict)
+	}
+
+	return b.String()
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
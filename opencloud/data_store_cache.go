@@ -0,0 +1,262 @@
+package opencloud
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DataStoreCacheItem is a cached entry and the time it has to be revalidated.
+type DataStoreCacheItem struct {
+	Entry      DataStoreEntry
+	ExpireTime time.Time
+}
+
+// DataStoreCacheStore stores cached entries. Implementations must be safe for concurrent use.
+type DataStoreCacheStore interface {
+	Get(key string) (*DataStoreCacheItem, bool)
+	Set(key string, item *DataStoreCacheItem)
+	Delete(key string)
+}
+
+// LRUDataStoreCacheStore is an in-memory store that evicts the least recently used entry once it is full.
+type LRUDataStoreCacheStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type lruDataStoreCacheElement struct {
+	key  string
+	item *DataStoreCacheItem
+}
+
+// NewLRUDataStoreCacheStore will create an in-memory store that holds up to capacity entries.
+func NewLRUDataStoreCacheStore(capacity int) *LRUDataStoreCacheStore {
+	return &LRUDataStoreCacheStore{
+		capacity: max(capacity, 1),
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (s *LRUDataStoreCacheStore) Get(key string) (*DataStoreCacheItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	s.order.MoveToFront(element)
+	return element.Value.(*lruDataStoreCacheElement).item, true
+}
+
+func (s *LRUDataStoreCacheStore) Set(key string, item *DataStoreCacheItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		element.Value.(*lruDataStoreCacheElement).item = item
+		s.order.MoveToFront(element)
+		return
+	}
+
+	s.items[key] = s.order.PushFront(&lruDataStoreCacheElement{key: key, item: item})
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*lruDataStoreCacheElement).key)
+	}
+}
+
+func (s *LRUDataStoreCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		s.order.Remove(element)
+		delete(s.items, key)
+	}
+}
+
+type DataStoreCacheStats struct {
+	// Hits are reads served from the cache without a request.
+	Hits int64
+	// Revalidations are stale reads that were confirmed to be unchanged by their etag.
+	Revalidations int64
+	// Misses are reads that had to fetch the entry.
+	Misses int64
+	// Invalidations are entries removed because they were written through the client, or a write failed on a stale etag.
+	Invalidations int64
+}
+
+// DataStoreCache is a read-through cache for GetDataStoreEntry. Enable it with WithDataStoreCache.
+type DataStoreCache struct {
+	store DataStoreCacheStore
+
+	// DefaultTTL is how long an entry is served without revalidation.
+	DefaultTTL time.Duration
+	// TTLs overrides DefaultTTL for specific data stores.
+	TTLs map[string]time.Duration
+
+	hits, revalidations, misses, invalidations atomic.Int64
+}
+
+// NewDataStoreCache will create a cache that uses the store, or an LRU store holding 10,000 entries when the store is nil.
+func NewDataStoreCache(store DataStoreCacheStore, defaultTTL time.Duration) *DataStoreCache {
+	if store == nil {
+		store = NewLRUDataStoreCacheStore(10_000)
+	}
+
+	return &DataStoreCache{
+		store:      store,
+		DefaultTTL: defaultTTL,
+		TTLs:       make(map[string]time.Duration),
+	}
+}
+
+// WithDataStoreCache will serve GetDataStoreEntry from the cache, and invalidate entries that are written through the client.
+func WithDataStoreCache(cache *DataStoreCache) ClientOpts {
+	return func(c *Client) {
+		c.dataStoreCache = cache
+	}
+}
+
+// Stats will return the hit and miss statistics of the cache.
+func (c *DataStoreCache) Stats() DataStoreCacheStats {
+	return DataStoreCacheStats{
+		Hits:          c.hits.Load(),
+		Revalidations: c.revalidations.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+	}
+}
+
+func (c *DataStoreCache) ttl(dataStoreId string) time.Duration {
+	if ttl, ok := c.TTLs[dataStoreId]; ok {
+		return ttl
+	}
+
+	return c.DefaultTTL
+}
+
+func dataStoreCacheKey(universeId, dataStoreId string, scope *string, entryId string) string {
+	scopeId := "global"
+	if scope != nil {
+		scopeId = *scope
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s", universeId, dataStoreId, scopeId, entryId)
+}
+
+// Invalidate will remove an entry from the cache.
+func (c *DataStoreCache) Invalidate(universeId, dataStoreId string, scope *string, entryId string) {
+	c.invalidations.Add(1)
+	c.store.Delete(dataStoreCacheKey(universeId, dataStoreId, scope, entryId))
+}
+
+// get will serve an entry from the cache, revalidating it with its etag once it is stale.
+func (c *DataStoreCache) get(ctx context.Context, client *Client, u, universeId, dataStoreId string, scope *string, entryId string) (*DataStoreEntry, *Response, error) {
+	key := dataStoreCacheKey(universeId, dataStoreId, scope, entryId)
+
+	item, cached := c.store.Get(key)
+	if cached && time.Now().Before(item.ExpireTime) {
+		c.hits.Add(1)
+		entry := copyDataStoreEntry(item.Entry)
+		return &entry, cachedResponse(), nil
+	}
+
+	req, err := client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cached {
+		req.Header.Set("If-None-Match", item.Entry.Etag)
+	}
+
+	httpResp, err := client.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer httpResp.Body.Close()
+	resp := &Response{Response: httpResp}
+
+	if cached && httpResp.StatusCode == http.StatusNotModified {
+		c.revalidations.Add(1)
+		c.store.Set(key, &DataStoreCacheItem{Entry: item.Entry, ExpireTime: time.Now().Add(c.ttl(dataStoreId))})
+
+		entry := copyDataStoreEntry(item.Entry)
+		return &entry, resp, nil
+	}
+
+	entry := new(DataStoreEntry)
+	if err := json.NewDecoder(httpResp.Body).Decode(entry); err != nil {
+		return nil, resp, err
+	}
+
+	if checkResponse(resp) != nil {
+		c.store.Delete(key)
+		c.misses.Add(1)
+		return entry, resp, nil
+	}
+
+	if cached && entry.Etag == item.Entry.Etag {
+		c.revalidations.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	c.store.Set(key, &DataStoreCacheItem{Entry: copyDataStoreEntry(*entry), ExpireTime: time.Now().Add(c.ttl(dataStoreId))})
+
+	return entry, resp, nil
+}
+
+// copyDataStoreEntry will deep copy an entry, so cached entries are not shared with callers that change them.
+func copyDataStoreEntry(entry DataStoreEntry) DataStoreEntry {
+	if entry.Value != nil {
+		var value any
+		if err := decodeValue(entry.Value, &value); err == nil {
+			entry.Value = value
+		}
+	}
+	if entry.Attributes != nil {
+		var attributes map[string]any
+		if err := decodeValue(entry.Attributes, &attributes); err == nil {
+			entry.Attributes = attributes
+		}
+	}
+	entry.Users = slices.Clone(entry.Users)
+
+	return entry
+}
+
+// cachedResponse is returned for reads served from the cache, so callers checking the status code do not need to handle a nil response.
+func cachedResponse() *Response {
+	return &Response{Response: &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Goblox-Cache": {"HIT"}},
+	}}
+}
+
+type skipDataStoreCacheKey struct{}
+
+// withoutDataStoreCache will make GetDataStoreEntry skip the cache, for reads whose etag is used for a conditional write.
+func withoutDataStoreCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipDataStoreCacheKey{}, true)
+}
+
+// cacheable will report if a read of the entry can use the cache. Reads of a specific revision always skip the cache.
+func (c *DataStoreCache) cacheable(ctx context.Context, entryId string) bool {
+	skip, _ := ctx.Value(skipDataStoreCacheKey{}).(bool)
+	return c != nil && !skip && !strings.Contains(entryId, "@")
+}
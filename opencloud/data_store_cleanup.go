@@ -0,0 +1,175 @@
+package opencloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+)
+
+// DataStoreCleanupConfirmFunc is called with the data stores that are about to be deleted. Nothing is deleted unless it returns true.
+type DataStoreCleanupConfirmFunc func(candidates []DataStore) bool
+
+type DataStoreCleanupOptions struct {
+	// Pattern is a glob pattern matched against the data store ID, such as "test-*".
+	Pattern string
+	// Confirm is required, and is called once with every data store that passed the safeguards.
+	Confirm DataStoreCleanupConfirmFunc
+
+	// RecentWriteWindow will protect data stores that have an entry written within the window.
+	// Defaults to 7 days.
+	RecentWriteWindow time.Duration
+	// MaxEntriesChecked is the amount of entries read from each data store, across all of its scopes, to look for recent writes.
+	// Data stores with more entries than this are protected, since they can not be proven to be inactive. Defaults to 100.
+	MaxEntriesChecked int
+}
+
+type DataStoreCleanupSkip struct {
+	DataStore DataStore
+	Reason    string
+}
+
+type DataStoreCleanupFailure struct {
+	DataStore DataStore
+	Error     error
+}
+
+type DataStoreCleanupReport struct {
+	Confirmed bool
+	Deleted   []DataStore
+	Protected []DataStoreCleanupSkip
+	Failed    []DataStoreCleanupFailure
+}
+
+// CleanupDataStores will delete the data stores of a universe that match a pattern, after the deletion is confirmed.
+// Data stores with recent writes are protected and never passed to the confirmation callback.
+//
+// Deleted data stores can be restored with UndeleteDataStore until they expire.
+func (s *DataAndMemoryStoreService) CleanupDataStores(ctx context.Context, universeId string, opts DataStoreCleanupOptions) (*DataStoreCleanupReport, error) {
+	if opts.Confirm == nil {
+		return nil, fmt.Errorf("CleanupDataStores: a confirmation callback is required")
+	}
+	if _, err := path.Match(opts.Pattern, ""); err != nil {
+		return nil, fmt.Errorf("CleanupDataStores: invalid pattern %q: %w", opts.Pattern, err)
+	}
+
+	window := opts.RecentWriteWindow
+	if window == 0 {
+		window = 7 * 24 * time.Hour
+	}
+	maxEntries := opts.MaxEntriesChecked
+	if maxEntries == 0 {
+		maxEntries = 100
+	}
+
+	report := new(DataStoreCleanupReport)
+	var candidates []DataStore
+
+	listOpts := &OptionsWithFilter{MaxPageSize: Pointer(100)}
+	for {
+		dataStores, resp, err := s.ListDataStores(ctx, universeId, listOpts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return report, err
+		}
+
+		for _, dataStore := range dataStores.DataStores {
+			if matched, _ := path.Match(opts.Pattern, dataStore.ID); !matched {
+				continue
+			}
+
+			reason, err := s.recentDataStoreWrite(ctx, universeId, dataStore.ID, time.Now().Add(-window), maxEntries)
+			if err != nil {
+				return report, err
+			}
+			if reason != "" {
+				report.Protected = append(report.Protected, DataStoreCleanupSkip{DataStore: dataStore, Reason: reason})
+				continue
+			}
+
+			candidates = append(candidates, dataStore)
+		}
+
+		if dataStores.NextPageToken == "" {
+			break
+		}
+		listOpts.PageToken = Pointer(dataStores.NextPageToken)
+	}
+
+	if len(candidates) == 0 || !opts.Confirm(candidates) {
+		return report, nil
+	}
+	report.Confirmed = true
+
+	for _, dataStore := range candidates {
+		deleted, resp, err := s.DeleteDataStore(ctx, universeId, dataStore.ID)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			report.Failed = append(report.Failed, DataStoreCleanupFailure{DataStore: dataStore, Error: err})
+			continue
+		}
+
+		report.Deleted = append(report.Deleted, *deleted)
+	}
+
+	return report, nil
+}
+
+// recentDataStoreWrite will return the reason a data store has to be protected, or an empty string if it was not written to since the time.
+// Entries of every scope are checked, and entries that were deleted after they were listed are not recent writes.
+func (s *DataAndMemoryStoreService) recentDataStoreWrite(ctx context.Context, universeId, dataStoreId string, since time.Time, maxEntries int) (string, error) {
+	checked := 0
+
+	// The v2 API can only list the entries of a single scope, so the keys of all scopes are listed with the v1 API.
+	opts := &DataStoreV1ListEntriesOptions{AllScopes: Pointer(true), Limit: Pointer(min(maxEntries+1, 100))}
+	for {
+		entries, resp, err := s.client.DataStoreV1.ListEntries(ctx, universeId, dataStoreId, opts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		for _, listed := range entries.DataStoreEntries {
+			if checked == maxEntries {
+				return fmt.Sprintf("more than %d entries, recent writes could not be ruled out", maxEntries), nil
+			}
+			checked++
+
+			var scope *string
+			if scopeId := dataStoreEntryScope(listed.Path); scopeId != "global" {
+				scope = Pointer(scopeId)
+			}
+
+			entry, resp, err := s.GetDataStoreEntry(withoutDataStoreCache(ctx), universeId, dataStoreId, scope, listed.ID)
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if isStatus(err, http.StatusNotFound) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+
+			written, err := time.Parse(time.RFC3339Nano, entry.RevisionCreationTime)
+			if err != nil {
+				return "", err
+			}
+			if written.After(since) {
+				return fmt.Sprintf("entry %q was written at %s", listed.Path, entry.RevisionCreationTime), nil
+			}
+		}
+
+		if entries.NextPageToken == "" {
+			return "", nil
+		}
+		opts.Cursor = Pointer(entries.NextPageToken)
+	}
+}
@@ -0,0 +1,272 @@
+package opencloud
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DataStoreErasureUserIDPlaceholder is replaced with the user ID in key templates.
+const DataStoreErasureUserIDPlaceholder = "{userId}"
+
+type DataStoreErasureTarget struct {
+	DataStore string
+	// Scopes to search in the data store. Every scope of the data store is searched when empty.
+	Scopes []string
+	// KeyTemplates are keys that belong to the user, such as "Player_{userId}".
+	// These are used in addition to DataStoreErasureOptions.KeyTemplates.
+	KeyTemplates []string
+}
+
+type DataStoreErasureOptions struct {
+	// Targets are the data stores to search.
+	Targets []DataStoreErasureTarget
+	// AllDataStores will search every scope of every data store in the universe that is not already a target.
+	AllDataStores bool
+	// KeyTemplates are applied to every target.
+	KeyTemplates []string
+	// MatchUsers will scan every entry of the targets and match entries that have the user in their users metadata.
+	// This reads every entry, so it is a lot slower than only using key templates.
+	MatchUsers bool
+	// DryRun will report the matching entries without deleting them.
+	DryRun bool
+}
+
+type DataStoreErasureMatch string
+
+const (
+	DataStoreErasureMatchKeyTemplate DataStoreErasureMatch = "KEY_TEMPLATE"
+	DataStoreErasureMatchUsers       DataStoreErasureMatch = "USERS"
+)
+
+type DataStoreErasureRecord struct {
+	DataStore  string                `json:"dataStore"`
+	Scope      string                `json:"scope,omitempty"`
+	ID         string                `json:"id"`
+	MatchedBy  DataStoreErasureMatch `json:"matchedBy"`
+	RevisionID string                `json:"revisionId,omitempty"`
+	Deleted    bool                  `json:"deleted"`
+	DeleteTime string                `json:"deleteTime,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// DataStoreErasureReport is an auditable record of every entry that was removed for an erasure request.
+type DataStoreErasureReport struct {
+	Universe     string                   `json:"universe"`
+	UserID       string                   `json:"userId"`
+	DryRun       bool                     `json:"dryRun"`
+	StartTime    string                   `json:"startTime"`
+	EndTime      string                   `json:"endTime"`
+	DataStores   []string                 `json:"dataStores"`
+	Records      []DataStoreErasureRecord `json:"records"`
+	FailureCount int                      `json:"failureCount"`
+}
+
+// EraseUserData will find and delete every entry tied to a user across the configured data stores and scopes.
+// Entries are matched by key templates and, optionally, by the users metadata of the entry.
+// Targets without scopes are searched in every scope, which are found by listing the keys of the whole data store.
+//
+// The returned error is only set when the data stores could not be searched, failed deletions are recorded in the report.
+func (s *DataAndMemoryStoreService) EraseUserData(ctx context.Context, universeId, userId string, opts DataStoreErasureOptions) (*DataStoreErasureReport, error) {
+	report := &DataStoreErasureReport{
+		Universe:  universeId,
+		UserID:    userId,
+		DryRun:    opts.DryRun,
+		StartTime: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+
+	targets := slices.Clone(opts.Targets)
+	if opts.AllDataStores {
+		listOpts := &OptionsWithFilter{MaxPageSize: Pointer(100)}
+		for {
+			dataStores, resp, err := s.ListDataStores(ctx, universeId, listOpts)
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				return report, err
+			}
+
+			for _, dataStore := range dataStores.DataStores {
+				known := slices.ContainsFunc(targets, func(target DataStoreErasureTarget) bool {
+					return target.DataStore == dataStore.ID
+				})
+				if !known {
+					targets = append(targets, DataStoreErasureTarget{DataStore: dataStore.ID})
+				}
+			}
+
+			if dataStores.NextPageToken == "" {
+				break
+			}
+			listOpts.PageToken = Pointer(dataStores.NextPageToken)
+		}
+	}
+
+	for _, target := range targets {
+		report.DataStores = append(report.DataStores, target.DataStore)
+
+		scopes := target.Scopes
+		if len(scopes) == 0 {
+			var err error
+			if scopes, err = s.listDataStoreScopes(ctx, universeId, target.DataStore); err != nil {
+				return report, err
+			}
+		}
+
+		templates := append(slices.Clone(opts.KeyTemplates), target.KeyTemplates...)
+		for _, scopeId := range scopes {
+			var scope *string
+			if scopeId != "" && scopeId != "global" {
+				scope = Pointer(scopeId)
+			}
+
+			matches, err := s.findUserEntries(ctx, universeId, target.DataStore, scope, userId, templates, opts.MatchUsers)
+			if err != nil {
+				return report, err
+			}
+
+			for _, record := range matches {
+				record.Scope = scopeId
+				if !opts.DryRun {
+					resp, err := s.DeleteDataStoreEntry(ctx, universeId, target.DataStore, scope, record.ID)
+					if err == nil {
+						err = checkResponse(resp)
+					}
+
+					if err != nil {
+						record.Error = err.Error()
+						report.FailureCount++
+					} else {
+						record.Deleted = true
+						record.DeleteTime = time.Now().UTC().Format(time.RFC3339Nano)
+					}
+				}
+
+				report.Records = append(report.Records, record)
+			}
+		}
+	}
+
+	report.EndTime = time.Now().UTC().Format(time.RFC3339Nano)
+	return report, nil
+}
+
+// findUserEntries will return the entries in a data store scope that match the key templates or have the user in their users metadata.
+func (s *DataAndMemoryStoreService) findUserEntries(ctx context.Context, universeId, dataStoreId string, scope *string, userId string, templates []string, matchUsers bool) ([]DataStoreErasureRecord, error) {
+	var records []DataStoreErasureRecord
+	matched := make(map[string]bool)
+
+	for _, template := range templates {
+		key := strings.ReplaceAll(template, DataStoreErasureUserIDPlaceholder, userId)
+		if matched[key] {
+			continue
+		}
+
+		entry, resp, err := s.GetDataStoreEntry(ctx, universeId, dataStoreId, scope, key)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if isStatus(err, http.StatusNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		matched[key] = true
+		records = append(records, DataStoreErasureRecord{
+			DataStore:  dataStoreId,
+			ID:         key,
+			MatchedBy:  DataStoreErasureMatchKeyTemplate,
+			RevisionID: entry.RevisionID,
+		})
+	}
+
+	if !matchUsers {
+		return records, nil
+	}
+
+	err := s.forEachDataStoreEntry(ctx, universeId, dataStoreId, scope, nil, func(listed DataStoreEntry) error {
+		if matched[listed.ID] {
+			return nil
+		}
+
+		// Entries that are listed do not include their metadata, so every entry has to be fetched.
+		entry, resp, err := s.GetDataStoreEntry(ctx, universeId, dataStoreId, scope, listed.ID)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if isStatus(err, http.StatusNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(entry.Users, userId) && !slices.Contains(entry.Users, "users/"+userId) {
+			return nil
+		}
+
+		matched[listed.ID] = true
+		records = append(records, DataStoreErasureRecord{
+			DataStore:  dataStoreId,
+			ID:         listed.ID,
+			MatchedBy:  DataStoreErasureMatchUsers,
+			RevisionID: entry.RevisionID,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// listDataStoreScopes will find every scope of a data store that has entries, by listing the keys of all scopes.
+// The v2 API can only list the entries of a single scope, so this uses the v1 API.
+func (s *DataAndMemoryStoreService) listDataStoreScopes(ctx context.Context, universeId, dataStoreId string) ([]string, error) {
+	seen := make(map[string]bool)
+	var scopes []string
+
+	opts := &DataStoreV1ListEntriesOptions{AllScopes: Pointer(true), Limit: Pointer(100)}
+	for {
+		entries, resp, err := s.client.DataStoreV1.ListEntries(ctx, universeId, dataStoreId, opts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries.DataStoreEntries {
+			scope := dataStoreEntryScope(entry.Path)
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+
+		if entries.NextPageToken == "" {
+			break
+		}
+		opts.Cursor = Pointer(entries.NextPageToken)
+	}
+
+	slices.Sort(scopes)
+	return scopes, nil
+}
+
+// dataStoreEntryScope will return the scope from the path of an entry, or "global" if the path does not include one.
+func dataStoreEntryScope(path string) string {
+	_, rest, ok := strings.Cut(path, "/scopes/")
+	if !ok {
+		return "global"
+	}
+
+	scope, _, _ := strings.Cut(rest, "/")
+	return scope
+}
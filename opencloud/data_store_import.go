@@ -0,0 +1,289 @@
+package opencloud
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DataStoreArchiveEntry is a single data store entry stored inside of a DataStoreArchive.
+type DataStoreArchiveEntry struct {
+	DataStore            string         `json:"dataStore"`
+	Scope                string         `json:"scope,omitempty"`
+	ID                   string         `json:"id"`
+	Value                any            `json:"value"`
+	Users                []string       `json:"users,omitempty"`
+	Attributes           map[string]any `json:"attributes,omitempty"`
+	RevisionCreationTime string         `json:"revisionCreationTime,omitempty"`
+}
+
+// DataStoreArchive is a portable copy of data store entries that can be loaded into any universe.
+type DataStoreArchive struct {
+	Universe   string                  `json:"universe"`
+	CreateTime string                  `json:"createTime"`
+	Entries    []DataStoreArchiveEntry `json:"entries"`
+}
+
+// NewDataStoreArchiveEntry will convert an entry fetched from the API into an archive entry.
+func NewDataStoreArchiveEntry(dataStoreId string, scope *string, entry DataStoreEntry) DataStoreArchiveEntry {
+	archiveEntry := DataStoreArchiveEntry{
+		DataStore:            dataStoreId,
+		ID:                   entry.ID,
+		Value:                entry.Value,
+		Users:                entry.Users,
+		Attributes:           entry.Attributes,
+		RevisionCreationTime: entry.RevisionCreationTime,
+	}
+	if scope != nil {
+		archiveEntry.Scope = *scope
+	}
+
+	return archiveEntry
+}
+
+// ReadDataStoreArchive will decode a JSON encoded archive.
+func ReadDataStoreArchive(r io.Reader) (*DataStoreArchive, error) {
+	archive := new(DataStoreArchive)
+	if err := json.NewDecoder(r).Decode(archive); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// WriteDataStoreArchive will encode the archive as JSON.
+func WriteDataStoreArchive(w io.Writer, archive *DataStoreArchive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(archive)
+}
+
+type DataStoreImportConflictPolicy string
+
+const (
+	// DataStoreImportConflictSkip will leave entries that already exist untouched.
+	DataStoreImportConflictSkip DataStoreImportConflictPolicy = "SKIP"
+	// DataStoreImportConflictOverwrite will always replace entries that already exist.
+	DataStoreImportConflictOverwrite DataStoreImportConflictPolicy = "OVERWRITE"
+	// DataStoreImportConflictOverwriteIfOlder will only replace entries whose latest revision is older than the archived revision.
+	DataStoreImportConflictOverwriteIfOlder DataStoreImportConflictPolicy = "OVERWRITE_IF_OLDER"
+)
+
+type DataStoreImportAction string
+
+const (
+	DataStoreImportActionCreated     DataStoreImportAction = "CREATED"
+	DataStoreImportActionOverwritten DataStoreImportAction = "OVERWRITTEN"
+	DataStoreImportActionSkipped     DataStoreImportAction = "SKIPPED"
+	DataStoreImportActionFiltered    DataStoreImportAction = "FILTERED"
+	DataStoreImportActionFailed      DataStoreImportAction = "FAILED"
+)
+
+type DataStoreImportOptions struct {
+	// ConflictPolicy defaults to DataStoreImportConflictSkip.
+	ConflictPolicy DataStoreImportConflictPolicy
+	// DryRun will report what would happen without writing anything.
+	DryRun bool
+
+	// IncludeDataStores will only import entries from these data stores. All data stores are imported when empty.
+	IncludeDataStores []string
+	ExcludeDataStores []string
+	// IncludeKeyPrefixes will only import entries with a key starting with one of these prefixes. All keys are imported when empty.
+	IncludeKeyPrefixes []string
+	ExcludeKeyPrefixes []string
+}
+
+func (o *DataStoreImportOptions) matches(entry DataStoreArchiveEntry) bool {
+	if len(o.IncludeDataStores) > 0 && !slices.Contains(o.IncludeDataStores, entry.DataStore) {
+		return false
+	}
+	if slices.Contains(o.ExcludeDataStores, entry.DataStore) {
+		return false
+	}
+
+	hasPrefix := func(prefix string) bool {
+		return strings.HasPrefix(entry.ID, prefix)
+	}
+	if len(o.IncludeKeyPrefixes) > 0 && !slices.ContainsFunc(o.IncludeKeyPrefixes, hasPrefix) {
+		return false
+	}
+	if slices.ContainsFunc(o.ExcludeKeyPrefixes, hasPrefix) {
+		return false
+	}
+
+	return true
+}
+
+type DataStoreImportResult struct {
+	DataStore string
+	Scope     string
+	ID        string
+	Action    DataStoreImportAction
+	Error     error
+}
+
+type DataStoreImportReport struct {
+	DryRun  bool
+	Results []DataStoreImportResult
+
+	Created     int
+	Overwritten int
+	Skipped     int
+	Filtered    int
+	Failed      int
+}
+
+func (r *DataStoreImportReport) add(result DataStoreImportResult) {
+	r.Results = append(r.Results, result)
+
+	switch result.Action {
+	case DataStoreImportActionCreated:
+		r.Created++
+	case DataStoreImportActionOverwritten:
+		r.Overwritten++
+	case DataStoreImportActionSkipped:
+		r.Skipped++
+	case DataStoreImportActionFiltered:
+		r.Filtered++
+	case DataStoreImportActionFailed:
+		r.Failed++
+	}
+}
+
+// ImportDataStoreArchive will load the entries of an archive into the data stores of a specific universe.
+// Entries are written with CreateDataStoreEntry, and existing entries are resolved with the conflict policy and UpdateDataStoreEntry.
+//
+// The returned error is only set when the context is cancelled, failures for individual entries are recorded in the report.
+func (s *DataAndMemoryStoreService) ImportDataStoreArchive(ctx context.Context, universeId string, archive *DataStoreArchive, opts *DataStoreImportOptions) (*DataStoreImportReport, error) {
+	if opts == nil {
+		opts = &DataStoreImportOptions{}
+	}
+
+	policy := opts.ConflictPolicy
+	if policy == "" {
+		policy = DataStoreImportConflictSkip
+	}
+
+	report := &DataStoreImportReport{DryRun: opts.DryRun}
+	for _, entry := range archive.Entries {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		result := DataStoreImportResult{DataStore: entry.DataStore, Scope: entry.Scope, ID: entry.ID}
+		if !opts.matches(entry) {
+			result.Action = DataStoreImportActionFiltered
+			report.add(result)
+			continue
+		}
+
+		result.Action, result.Error = s.importDataStoreEntry(ctx, universeId, entry, policy, opts.DryRun)
+		if result.Error != nil {
+			result.Action = DataStoreImportActionFailed
+		}
+
+		report.add(result)
+	}
+
+	return report, nil
+}
+
+func (s *DataAndMemoryStoreService) importDataStoreEntry(ctx context.Context, universeId string, entry DataStoreArchiveEntry, policy DataStoreImportConflictPolicy, dryRun bool) (DataStoreImportAction, error) {
+	var scope *string
+	if entry.Scope != "" {
+		scope = Pointer(entry.Scope)
+	}
+
+	existing, resp, err := s.GetDataStoreEntry(withoutDataStoreCache(ctx), universeId, entry.DataStore, scope, entry.ID)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	switch {
+	case isStatus(err, http.StatusNotFound):
+		if dryRun {
+			return DataStoreImportActionCreated, nil
+		}
+
+		_, resp, err := s.CreateDataStoreEntry(ctx, universeId, entry.DataStore, scope, DataStoreEntryCreate{
+			Value:      Pointer(entry.Value),
+			Users:      optionalSlice(entry.Users),
+			Attributes: optionalMap(entry.Attributes),
+		}, &DataStoreEntryCreateOptions{ID: Pointer(entry.ID)})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+
+		return DataStoreImportActionCreated, err
+	case err != nil:
+		return DataStoreImportActionFailed, err
+	}
+
+	switch policy {
+	case DataStoreImportConflictSkip:
+		return DataStoreImportActionSkipped, nil
+	case DataStoreImportConflictOverwriteIfOlder:
+		existingTime, err := time.Parse(time.RFC3339Nano, existing.RevisionCreationTime)
+		if err != nil {
+			return DataStoreImportActionFailed, err
+		}
+
+		archivedTime, err := time.Parse(time.RFC3339Nano, entry.RevisionCreationTime)
+		if err != nil {
+			return DataStoreImportActionFailed, err
+		}
+
+		if !existingTime.Before(archivedTime) {
+			return DataStoreImportActionSkipped, nil
+		}
+	}
+
+	if dryRun {
+		return DataStoreImportActionOverwritten, nil
+	}
+
+	// Users and attributes are always sent, so an archived entry without them clears the existing ones.
+	users, attributes := entry.Users, entry.Attributes
+	if users == nil {
+		users = []string{}
+	}
+	if attributes == nil {
+		attributes = map[string]any{}
+	}
+
+	_, resp, err = s.UpdateDataStoreEntry(ctx, universeId, entry.DataStore, scope, entry.ID, DataStoreEntryUpdate{
+		Etag:       Pointer(existing.Etag),
+		Value:      Pointer(entry.Value),
+		Users:      &users,
+		Attributes: &attributes,
+	}, nil)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	return DataStoreImportActionOverwritten, err
+}
+
+// optionalSlice will return nil for an empty slice so it is omitted from the request.
+func optionalSlice[T any](v []T) *[]T {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return &v
+}
+
+// optionalMap will return nil for an empty map so it is omitted from the request.
+func optionalMap[K comparable, V any](v map[K]V) *map[K]V {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return &v
+}
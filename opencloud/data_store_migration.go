@@ -0,0 +1,336 @@
+package opencloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+)
+
+// DefaultDataStoreSchemaVersionAttribute is the entry attribute used to record the schema version of a value.
+const DefaultDataStoreSchemaVersionAttribute = "schemaVersion"
+
+// DataStoreMigrationFunc will transform a value from the previous schema version into the next one.
+type DataStoreMigrationFunc func(value any) (any, error)
+
+type DataStoreMigration struct {
+	Version int
+	Name    string
+	Migrate DataStoreMigrationFunc
+}
+
+// DataStoreMigrationCheckpoint will persist the progress of a bulk migration so an interrupted run can be resumed.
+type DataStoreMigrationCheckpoint interface {
+	// Load will return the page token to resume from, or an empty string to start from the beginning.
+	Load(ctx context.Context) (string, error)
+	// Save will store the page token of the next page that still has to be migrated.
+	Save(ctx context.Context, pageToken string) error
+}
+
+// FileDataStoreMigrationCheckpoint will store the progress of a bulk migration in a local JSON file.
+type FileDataStoreMigrationCheckpoint struct {
+	Path string
+}
+
+type fileDataStoreMigrationCheckpoint struct {
+	PageToken string `json:"pageToken"`
+}
+
+func (c *FileDataStoreMigrationCheckpoint) Load(ctx context.Context) (string, error) {
+	b, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var checkpoint fileDataStoreMigrationCheckpoint
+	if err := json.Unmarshal(b, &checkpoint); err != nil {
+		return "", err
+	}
+
+	return checkpoint.PageToken, nil
+}
+
+func (c *FileDataStoreMigrationCheckpoint) Save(ctx context.Context, pageToken string) error {
+	b, err := json.Marshal(fileDataStoreMigrationCheckpoint{PageToken: pageToken})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.Path, b, 0o644)
+}
+
+// DataStoreMigrator will apply ordered, versioned migrations to the entries of a data store.
+type DataStoreMigrator struct {
+	service     *DataAndMemoryStoreService
+	universeId  string
+	dataStoreId string
+	scope       *string
+	migrations  []DataStoreMigration
+
+	// VersionAttribute is the entry attribute used to record the schema version.
+	// Defaults to DefaultDataStoreSchemaVersionAttribute.
+	VersionAttribute string
+	// MaxConflictRetries is the amount of times an entry is re-read and migrated again when it was changed during the migration.
+	MaxConflictRetries int
+}
+
+// NewDataStoreMigrator will create a migrator for a specific data store under a specific universe.
+func (s *DataAndMemoryStoreService) NewDataStoreMigrator(universeId, dataStoreId string, scope *string) *DataStoreMigrator {
+	return &DataStoreMigrator{
+		service:            s,
+		universeId:         universeId,
+		dataStoreId:        dataStoreId,
+		scope:              scope,
+		VersionAttribute:   DefaultDataStoreSchemaVersionAttribute,
+		MaxConflictRetries: 3,
+	}
+}
+
+// Register will add a migration that upgrades values to the specified version.
+// Versions must be positive and unique, and they are applied in ascending order.
+func (m *DataStoreMigrator) Register(version int, name string, fn DataStoreMigrationFunc) error {
+	if version <= 0 {
+		return fmt.Errorf("migration %q: version must be positive", name)
+	}
+
+	i, found := slices.BinarySearchFunc(m.migrations, version, func(migration DataStoreMigration, version int) int {
+		return migration.Version - version
+	})
+	if found {
+		return fmt.Errorf("migration %q: version %d is already registered by %q", name, version, m.migrations[i].Name)
+	}
+
+	m.migrations = slices.Insert(m.migrations, i, DataStoreMigration{Version: version, Name: name, Migrate: fn})
+	return nil
+}
+
+// LatestVersion will return the highest registered schema version.
+func (m *DataStoreMigrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version will return the schema version recorded on an entry. Entries without a version are treated as version 0.
+func (m *DataStoreMigrator) Version(entry *DataStoreEntry) int {
+	switch version := entry.Attributes[m.VersionAttribute].(type) {
+	case float64:
+		return int(version)
+	case int:
+		return version
+	default:
+		return 0
+	}
+}
+
+// MigrateValue will apply every migration newer than the version to the value, returning the new value and version.
+// The migrations are given a copy of the value, so the value that was passed in is not changed.
+func (m *DataStoreMigrator) MigrateValue(value any, version int) (any, int, error) {
+	var copied any
+	if err := decodeValue(value, &copied); err != nil {
+		return nil, version, err
+	}
+	value = copied
+
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			continue
+		}
+
+		migrated, err := migration.Migrate(value)
+		if err != nil {
+			return nil, version, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+
+		value, version = migrated, migration.Version
+	}
+
+	return value, version, nil
+}
+
+// Migrate will lazily migrate a single entry to the latest version and return the up to date entry.
+// The entry is written back with its etag, and it is migrated again from the new value if it was changed concurrently.
+func (m *DataStoreMigrator) Migrate(ctx context.Context, entryId string) (*DataStoreEntry, error) {
+	entry, _, err := m.migrateWithRetries(ctx, entryId, false)
+	return entry, err
+}
+
+// migrateWithRetries will fetch and migrate an entry, starting over when the entry was changed concurrently.
+func (m *DataStoreMigrator) migrateWithRetries(ctx context.Context, entryId string, dryRun bool) (*DataStoreEntry, *DataStoreMigrationSample, error) {
+	var err error
+	for attempt := 0; attempt <= m.MaxConflictRetries; attempt++ {
+		var entry *DataStoreEntry
+		var resp *Response
+		entry, resp, err = m.service.GetDataStoreEntry(withoutDataStoreCache(ctx), m.universeId, m.dataStoreId, m.scope, entryId)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var sample *DataStoreMigrationSample
+		entry, sample, err = m.migrateEntry(ctx, entry, dryRun)
+		if !isConflict(err) {
+			return entry, sample, err
+		}
+	}
+
+	return nil, nil, err
+}
+
+// migrateEntry will migrate the entry if it is behind the latest version, returning the resulting entry and the sample of the change.
+func (m *DataStoreMigrator) migrateEntry(ctx context.Context, entry *DataStoreEntry, dryRun bool) (*DataStoreEntry, *DataStoreMigrationSample, error) {
+	version := m.Version(entry)
+	if version >= m.LatestVersion() {
+		return entry, nil, nil
+	}
+
+	value, newVersion, err := m.MigrateValue(entry.Value, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sample := &DataStoreMigrationSample{
+		ID:          entry.ID,
+		FromVersion: version,
+		ToVersion:   newVersion,
+		Before:      entry.Value,
+		After:       value,
+	}
+	if dryRun {
+		return entry, sample, nil
+	}
+
+	attributes := maps.Clone(entry.Attributes)
+	if attributes == nil {
+		attributes = make(map[string]any)
+	}
+	attributes[m.VersionAttribute] = newVersion
+
+	updated, resp, err := m.service.UpdateDataStoreEntry(ctx, m.universeId, m.dataStoreId, m.scope, entry.ID, DataStoreEntryUpdate{
+		Etag:       Pointer(entry.Etag),
+		Value:      Pointer(value),
+		Users:      optionalSlice(entry.Users),
+		Attributes: &attributes,
+	}, nil)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, sample, nil
+}
+
+// DataStoreMigrationSample is the before and after value of a single migrated entry.
+type DataStoreMigrationSample struct {
+	ID          string `json:"id"`
+	FromVersion int    `json:"fromVersion"`
+	ToVersion   int    `json:"toVersion"`
+	Before      any    `json:"before"`
+	After       any    `json:"after"`
+}
+
+type DataStoreMigrationRunOptions struct {
+	// DryRun will compute the migrated values without writing them.
+	DryRun bool
+	// SampleSize is the maximum amount of before/after samples kept in the report.
+	SampleSize int
+	// Checkpoint will be used to resume an interrupted run and record progress after every page.
+	Checkpoint DataStoreMigrationCheckpoint
+	// PageSize is the amount of entries listed per request.
+	PageSize *int
+}
+
+type DataStoreMigrationFailure struct {
+	ID    string
+	Error error
+}
+
+type DataStoreMigrationReport struct {
+	DryRun   bool
+	Scanned  int
+	Migrated int
+	UpToDate int
+	Failures []DataStoreMigrationFailure
+	Samples  []DataStoreMigrationSample
+}
+
+// Run will migrate every entry of the data store to the latest version.
+// Progress is saved to the checkpoint after every page, and it is not saved during a dry run.
+//
+// The returned error is only set when the entries could not be listed, the checkpoint failed or the context is cancelled.
+func (m *DataStoreMigrator) Run(ctx context.Context, opts *DataStoreMigrationRunOptions) (*DataStoreMigrationReport, error) {
+	if opts == nil {
+		opts = &DataStoreMigrationRunOptions{}
+	}
+
+	listOpts := &ListDataStoreEntriesOptions{MaxPageSize: opts.PageSize}
+	if opts.Checkpoint != nil {
+		pageToken, err := opts.Checkpoint.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if pageToken != "" {
+			listOpts.PageToken = Pointer(pageToken)
+		}
+	}
+
+	report := &DataStoreMigrationReport{DryRun: opts.DryRun}
+	for {
+		entries, resp, err := m.service.ListDataStoreEntries(ctx, m.universeId, m.dataStoreId, m.scope, listOpts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return report, err
+		}
+
+		for _, listed := range entries.DataStoreEntries {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			report.Scanned++
+
+			_, sample, err := m.migrateWithRetries(ctx, listed.ID, opts.DryRun)
+			switch {
+			case err != nil:
+				report.Failures = append(report.Failures, DataStoreMigrationFailure{ID: listed.ID, Error: err})
+			case sample == nil:
+				report.UpToDate++
+			default:
+				report.Migrated++
+				if len(report.Samples) < opts.SampleSize {
+					report.Samples = append(report.Samples, *sample)
+				}
+			}
+		}
+
+		if opts.Checkpoint != nil && !opts.DryRun {
+			if err := opts.Checkpoint.Save(ctx, entries.NextPageToken); err != nil {
+				return report, err
+			}
+		}
+
+		if entries.NextPageToken == "" {
+			return report, nil
+		}
+		listOpts.PageToken = Pointer(entries.NextPageToken)
+	}
+}
+
+// isConflict will check if the error was caused by an etag mismatch.
+func isConflict(err error) bool {
+	return isStatus(err, http.StatusPreconditionFailed) || isStatus(err, http.StatusConflict)
+}
@@ -0,0 +1,297 @@
+package opencloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/typical-developers/goblox/opencloud/filter"
+)
+
+// ErrNoRevisionBefore is returned when an entry has no revision at or before the requested restore time.
+var ErrNoRevisionBefore = errors.New("no revision exists at or before the requested time")
+
+type DataStoreRestoreStatus string
+
+const (
+	DataStoreRestoreStatusChanged   DataStoreRestoreStatus = "CHANGED"
+	DataStoreRestoreStatusUnchanged DataStoreRestoreStatus = "UNCHANGED"
+	DataStoreRestoreStatusSkipped   DataStoreRestoreStatus = "SKIPPED"
+	DataStoreRestoreStatusFailed    DataStoreRestoreStatus = "FAILED"
+)
+
+type DataStoreRestoreResult struct {
+	ID     string
+	Status DataStoreRestoreStatus
+	// Revision is the revision that was restored, if one was found.
+	Revision *DataStoreEntry
+	Error    error
+}
+
+// RestoreDataStoreEntryAt will roll an entry back to the revision that was in effect at a specific time.
+// The revision is written back with the etag of the current entry, so a concurrent write will cause the restore to fail instead of being overwritten.
+// When the revision is a deletion, the current entry is confirmed with its etag right before it is deleted.
+//
+// ErrNoRevisionBefore is returned when the entry did not exist at that time.
+func (s *DataAndMemoryStoreService) RestoreDataStoreEntryAt(ctx context.Context, universeId, dataStoreId string, scope *string, entryId string, t time.Time) (*DataStoreRestoreResult, error) {
+	result := &DataStoreRestoreResult{ID: entryId}
+
+	revision, err := s.findDataStoreEntryRevisionAt(ctx, universeId, dataStoreId, scope, entryId, t)
+	if err != nil {
+		return result, err
+	}
+	result.Revision = revision
+
+	current, resp, err := s.GetDataStoreEntry(withoutDataStoreCache(ctx), universeId, dataStoreId, scope, entryId)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return result, err
+	}
+	exists := err == nil
+
+	if revision.State == DataStoreEntryStateDeleted {
+		if !exists {
+			result.Status = DataStoreRestoreStatusUnchanged
+			return result, nil
+		}
+
+		// Deletes can not be made conditional, so the current revision is first confirmed with an etag write of its own value.
+		// This fails if the entry was written since it was read, which leaves only the time between both requests for a write to be lost.
+		_, resp, err := s.UpdateDataStoreEntry(ctx, universeId, dataStoreId, scope, entryId, DataStoreEntryUpdate{
+			Etag:  Pointer(current.Etag),
+			Value: Pointer(current.Value),
+		}, nil)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return result, err
+		}
+
+		resp, err = s.DeleteDataStoreEntry(ctx, universeId, dataStoreId, scope, entryId)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return result, err
+		}
+
+		result.Status = DataStoreRestoreStatusChanged
+		return result, nil
+	}
+
+	if exists && current.RevisionID == revision.RevisionID {
+		result.Status = DataStoreRestoreStatusUnchanged
+		return result, nil
+	}
+
+	// Users and attributes are always sent, so a revision without them clears the current ones.
+	users, attributes := revision.Users, revision.Attributes
+	if users == nil {
+		users = []string{}
+	}
+	if attributes == nil {
+		attributes = map[string]any{}
+	}
+
+	if !exists {
+		_, resp, err := s.CreateDataStoreEntry(ctx, universeId, dataStoreId, scope, DataStoreEntryCreate{
+			Value:      Pointer(revision.Value),
+			Users:      &users,
+			Attributes: &attributes,
+		}, &DataStoreEntryCreateOptions{ID: Pointer(entryId)})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return result, err
+		}
+
+		result.Status = DataStoreRestoreStatusChanged
+		return result, nil
+	}
+
+	if reflect.DeepEqual(current.Value, revision.Value) &&
+		(len(current.Users) == 0 && len(users) == 0 || reflect.DeepEqual(current.Users, users)) &&
+		(len(current.Attributes) == 0 && len(attributes) == 0 || reflect.DeepEqual(current.Attributes, attributes)) {
+		result.Status = DataStoreRestoreStatusUnchanged
+		return result, nil
+	}
+
+	_, resp, err = s.UpdateDataStoreEntry(ctx, universeId, dataStoreId, scope, entryId, DataStoreEntryUpdate{
+		Etag:       Pointer(current.Etag),
+		Value:      Pointer(revision.Value),
+		Users:      &users,
+		Attributes: &attributes,
+	}, nil)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.Status = DataStoreRestoreStatusChanged
+	return result, nil
+}
+
+// findDataStoreEntryRevisionAt will walk the revisions of an entry and fetch the newest revision created at or before the time.
+func (s *DataAndMemoryStoreService) findDataStoreEntryRevisionAt(ctx context.Context, universeId, dataStoreId string, scope *string, entryId string, t time.Time) (*DataStoreEntry, error) {
+	var found *DataStoreEntry
+	var foundTime time.Time
+
+	opts := &Options{MaxPageSize: Pointer(100)}
+	for {
+		revisions, resp, err := s.ListDataStoreEntryRevisions(ctx, universeId, dataStoreId, scope, entryId, opts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, revision := range revisions.DataStoreEntries {
+			created, err := time.Parse(time.RFC3339Nano, revision.RevisionCreationTime)
+			if err != nil {
+				return nil, err
+			}
+
+			if created.After(t) || (found != nil && !created.After(foundTime)) {
+				continue
+			}
+
+			found, foundTime = &revision, created
+		}
+
+		if revisions.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = Pointer(revisions.NextPageToken)
+	}
+
+	if found == nil {
+		return nil, ErrNoRevisionBefore
+	}
+
+	// Deleted revisions do not have a value to fetch.
+	if found.State == DataStoreEntryStateDeleted {
+		return found, nil
+	}
+
+	revision, resp, err := s.GetDataStoreEntry(ctx, universeId, dataStoreId, scope, fmt.Sprintf("%s@%s", entryId, found.RevisionID))
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+type DataStoreRestoreBatch struct {
+	// IDs are the entries to restore.
+	IDs []string
+	// Prefix will restore every entry with a key starting with the prefix, in addition to IDs.
+	Prefix *string
+}
+
+type DataStoreRestoreReport struct {
+	Changed   []DataStoreRestoreResult
+	Unchanged []DataStoreRestoreResult
+	Skipped   []DataStoreRestoreResult
+	Failed    []DataStoreRestoreResult
+}
+
+// RestoreDataStoreEntriesAt will roll multiple entries back to the revisions that were in effect at a specific time.
+// Entries with no revision before the time are reported as skipped.
+//
+// The returned error is only set when the entries could not be listed or the context is cancelled.
+func (s *DataAndMemoryStoreService) RestoreDataStoreEntriesAt(ctx context.Context, universeId, dataStoreId string, scope *string, batch DataStoreRestoreBatch, t time.Time) (*DataStoreRestoreReport, error) {
+	ids := batch.IDs
+	if batch.Prefix != nil {
+		prefixFilter, err := filter.DataStoreEntries().IDStartsWith(*batch.Prefix).Build()
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.forEachDataStoreEntry(ctx, universeId, dataStoreId, scope, &ListDataStoreEntriesOptions{
+			Filter:      Pointer(prefixFilter),
+			ShowDeleted: Pointer(true),
+		}, func(entry DataStoreEntry) error {
+			ids = append(ids, entry.ID)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := new(DataStoreRestoreReport)
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		result, err := s.RestoreDataStoreEntryAt(ctx, universeId, dataStoreId, scope, id, t)
+		switch {
+		case errors.Is(err, ErrNoRevisionBefore):
+			result.Status = DataStoreRestoreStatusSkipped
+			report.Skipped = append(report.Skipped, *result)
+		case err != nil:
+			result.Status, result.Error = DataStoreRestoreStatusFailed, err
+			report.Failed = append(report.Failed, *result)
+		case result.Status == DataStoreRestoreStatusUnchanged:
+			report.Unchanged = append(report.Unchanged, *result)
+		default:
+			report.Changed = append(report.Changed, *result)
+		}
+	}
+
+	return report, nil
+}
+
+// errStopIteration can be returned by the callback of forEachDataStoreEntry to stop paging early.
+var errStopIteration = errors.New("stop iteration")
+
+// forEachDataStoreEntry will page through every entry of a data store and call fn for each of them.
+func (s *DataAndMemoryStoreService) forEachDataStoreEntry(ctx context.Context, universeId, dataStoreId string, scope *string, opts *ListDataStoreEntriesOptions, fn func(entry DataStoreEntry) error) error {
+	if opts == nil {
+		opts = &ListDataStoreEntriesOptions{}
+	}
+	if opts.MaxPageSize == nil {
+		opts.MaxPageSize = Pointer(256)
+	}
+
+	for {
+		entries, resp, err := s.ListDataStoreEntries(ctx, universeId, dataStoreId, scope, opts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries.DataStoreEntries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+
+		if entries.NextPageToken == "" {
+			return nil
+		}
+		opts.PageToken = Pointer(entries.NextPageToken)
+	}
+}
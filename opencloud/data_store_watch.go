@@ -0,0 +1,322 @@
+package opencloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DataStoreWatchPosition is the latest revision of an entry that was delivered by WatchDataStoreEntry.
+type DataStoreWatchPosition struct {
+	RevisionID           string `json:"revisionId"`
+	RevisionCreationTime string `json:"revisionCreationTime"`
+}
+
+// DataStoreWatchCursor will persist the position of every watched entry, so revisions are not delivered again after a restart.
+type DataStoreWatchCursor interface {
+	// Load will return the position of an entry, or nil if the entry was not watched before.
+	Load(ctx context.Context, entryId string) (*DataStoreWatchPosition, error)
+	// Save will store the position of an entry after its change was delivered.
+	Save(ctx context.Context, entryId string, position DataStoreWatchPosition) error
+}
+
+// FileDataStoreWatchCursor will store the positions of watched entries in a local JSON file.
+type FileDataStoreWatchCursor struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (c *FileDataStoreWatchCursor) read() (map[string]DataStoreWatchPosition, error) {
+	positions := make(map[string]DataStoreWatchPosition)
+
+	b, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return positions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &positions); err != nil {
+		return nil, err
+	}
+
+	return positions, nil
+}
+
+func (c *FileDataStoreWatchCursor) Load(ctx context.Context, entryId string) (*DataStoreWatchPosition, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	positions, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	position, ok := positions[entryId]
+	if !ok {
+		return nil, nil
+	}
+
+	return &position, nil
+}
+
+func (c *FileDataStoreWatchCursor) Save(ctx context.Context, entryId string, position DataStoreWatchPosition) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	positions, err := c.read()
+	if err != nil {
+		return err
+	}
+	positions[entryId] = position
+
+	b, err := json.Marshal(positions)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.Path, b, 0o644)
+}
+
+// DataStoreEntryChange is delivered by WatchDataStoreEntry for every new revision of a watched entry.
+type DataStoreEntryChange struct {
+	ID string
+	// Revision is the new revision, including its value unless the entry was deleted.
+	Revision DataStoreEntry
+	// Previous is the revision before the change, or nil if it is not known.
+	Previous *DataStoreEntry
+	// Diff are the operations that turn the previous value into the new value.
+	Diff []JSONPatchOperation
+}
+
+type DataStoreWatchOptions struct {
+	// Cursor will be used to resume from the last delivered revision of every entry.
+	// Without a cursor, or for entries the cursor has not seen, the current revision is used as the starting point and is not delivered.
+	Cursor DataStoreWatchCursor
+	// MaxInterval is the longest the poll interval is backed off to when rate limited. Defaults to 16 times the interval.
+	MaxInterval time.Duration
+	// OnError is called when an entry could not be polled. The entry is polled again on the next interval.
+	OnError func(entryId string, err error)
+}
+
+type dataStoreWatchState struct {
+	position *DataStoreWatchPosition
+	previous *DataStoreEntry
+}
+
+// WatchDataStoreEntry will poll the revisions of the entries and deliver a change for every new revision, oldest first.
+// The interval is backed off while the API responds with 429 Too Many Requests, and recovers once polls succeed again.
+//
+// The channel is closed once the context is cancelled.
+func (s *DataAndMemoryStoreService) WatchDataStoreEntry(ctx context.Context, universeId, dataStoreId string, scope *string, keys []string, interval time.Duration, opts *DataStoreWatchOptions) (<-chan DataStoreEntryChange, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("WatchDataStoreEntry: interval must be greater than 0, got %s", interval)
+	}
+	if opts == nil {
+		opts = &DataStoreWatchOptions{}
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval == 0 {
+		maxInterval = 16 * interval
+	}
+
+	states := make(map[string]*dataStoreWatchState, len(keys))
+	for _, key := range keys {
+		state := new(dataStoreWatchState)
+		if opts.Cursor != nil {
+			position, err := opts.Cursor.Load(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			state.position = position
+		}
+		states[key] = state
+	}
+
+	changes := make(chan DataStoreEntryChange)
+	go func() {
+		defer close(changes)
+
+		current := interval
+		for {
+			var backoff time.Duration
+			for _, key := range keys {
+				err := s.pollDataStoreEntry(ctx, universeId, dataStoreId, scope, key, states[key], opts.Cursor, changes)
+				if ctx.Err() != nil {
+					return
+				}
+
+				if isStatus(err, http.StatusTooManyRequests) {
+					var respErr *ResponseError
+					errors.As(err, &respErr)
+					backoff = max(retryAfter(respErr.Response), current*2)
+					break
+				}
+				if err != nil && opts.OnError != nil {
+					opts.OnError(key, err)
+				}
+			}
+
+			if backoff > 0 {
+				current = min(backoff, maxInterval)
+			} else {
+				current = max(current/2, interval)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(current):
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// retryAfter will return the delay requested by the Retry-After header of a response, or 0 if it does not have one.
+func retryAfter(resp *Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// pollDataStoreEntry will deliver the revisions of an entry that are newer than its position.
+func (s *DataAndMemoryStoreService) pollDataStoreEntry(ctx context.Context, universeId, dataStoreId string, scope *string, entryId string, state *dataStoreWatchState, cursor DataStoreWatchCursor, changes chan<- DataStoreEntryChange) error {
+	var since time.Time
+	if state.position != nil {
+		t, err := time.Parse(time.RFC3339Nano, state.position.RevisionCreationTime)
+		if err != nil {
+			return err
+		}
+		since = t
+	}
+
+	var revisions []DataStoreEntry
+	var times []time.Time
+
+	// Revisions are listed newest first, so paging stops at the first revision that was already delivered.
+	opts := &Options{MaxPageSize: Pointer(100)}
+	for done := false; !done; {
+		list, resp, err := s.ListDataStoreEntryRevisions(ctx, universeId, dataStoreId, scope, entryId, opts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if isStatus(err, http.StatusNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, revision := range list.DataStoreEntries {
+			created, err := time.Parse(time.RFC3339Nano, revision.RevisionCreationTime)
+			if err != nil {
+				return err
+			}
+
+			if state.position != nil && (!created.After(since) || revision.RevisionID == state.position.RevisionID) {
+				done = true
+				continue
+			}
+
+			revisions = append(revisions, revision)
+			times = append(times, created)
+		}
+
+		// Without a position there is nothing to deliver, only the newest revision is needed as the starting point.
+		if state.position == nil || list.NextPageToken == "" {
+			done = true
+		}
+		opts.PageToken = Pointer(list.NextPageToken)
+	}
+
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	order := make([]int, len(revisions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return times[order[i]].Before(times[order[j]]) })
+
+	if state.position == nil {
+		newest := revisions[order[len(order)-1]]
+		state.position = &DataStoreWatchPosition{RevisionID: newest.RevisionID, RevisionCreationTime: newest.RevisionCreationTime}
+		if cursor != nil {
+			return cursor.Save(ctx, entryId, *state.position)
+		}
+		return nil
+	}
+
+	if state.previous == nil {
+		previous, err := s.getDataStoreEntryRevision(ctx, universeId, dataStoreId, scope, entryId, state.position.RevisionID)
+		if err != nil && !isStatus(err, http.StatusNotFound) {
+			return err
+		}
+		state.previous = previous
+	}
+
+	for _, i := range order {
+		revision := &revisions[i]
+		if revision.State != DataStoreEntryStateDeleted {
+			full, err := s.getDataStoreEntryRevision(ctx, universeId, dataStoreId, scope, entryId, revision.RevisionID)
+			if err != nil {
+				return err
+			}
+			revision = full
+		}
+
+		change := DataStoreEntryChange{ID: entryId, Revision: *revision, Previous: state.previous}
+		if state.previous != nil {
+			change.Diff = DiffJSON(state.previous.Value, revision.Value)
+		} else {
+			change.Diff = DiffJSON(nil, revision.Value)
+		}
+
+		select {
+		case changes <- change:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		state.previous = revision
+		state.position = &DataStoreWatchPosition{RevisionID: revision.RevisionID, RevisionCreationTime: revision.RevisionCreationTime}
+		if cursor != nil {
+			if err := cursor.Save(ctx, entryId, *state.position); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *DataAndMemoryStoreService) getDataStoreEntryRevision(ctx context.Context, universeId, dataStoreId string, scope *string, entryId, revisionId string) (*DataStoreEntry, error) {
+	entry, resp, err := s.GetDataStoreEntry(ctx, universeId, dataStoreId, scope, fmt.Sprintf("%s@%s", entryId, revisionId))
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
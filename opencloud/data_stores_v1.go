@@ -0,0 +1,521 @@
+package opencloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+)
+
+var (
+	// ErrContentMD5Mismatch is returned when the content-md5 of an entry does not match its value.
+	ErrContentMD5Mismatch = errors.New("content-md5 does not match the entry value")
+	// ErrContentMD5Missing is returned when an entry is returned without a content-md5 header, so its value can not be checked.
+	ErrContentMD5Missing = errors.New("content-md5 header is missing")
+)
+
+// DataStoreV1Service will handle communciation with the actions related to the v1 Standard Data Stores API.
+// Results are mapped onto the same DataStoreEntry shape as the v2 API, so code can be migrated gradually.
+//
+// Roblox Open Cloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore
+type DataStoreV1Service service
+
+type DataStoreV1 struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdTime"`
+}
+
+type DataStoreV1List struct {
+	DataStores     []DataStoreV1 `json:"datastores"`
+	NextPageCursor string        `json:"nextPageCursor"`
+}
+
+type DataStoreV1ListOptions struct {
+	Cursor *string `url:"cursor,omitempty"`
+	Limit  *int    `url:"limit,omitempty"`
+	Prefix *string `url:"prefix,omitempty"`
+}
+
+// ListDataStores will fetch a list of data stores for a specific universe.
+//
+// Required scopes: universe-datastores.control:list
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#List-Data-Stores
+//
+// [GET] /datastores/v1/universes/{universeId}/standard-datastores
+func (s *DataStoreV1Service) ListDataStores(ctx context.Context, universeId string, opts *DataStoreV1ListOptions) (*DataStoreV1List, *Response, error) {
+	u := fmt.Sprintf("/datastores/v1/universes/%s/standard-datastores", universeId)
+
+	u, err := addOpts(u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataStoreList := new(DataStoreV1List)
+	resp, err := s.client.Do(ctx, req, dataStoreList)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return dataStoreList, resp, nil
+}
+
+type DataStoreV1ListEntriesOptions struct {
+	Scope     *string `url:"scope,omitempty"`
+	AllScopes *bool   `url:"allScopes,omitempty"`
+	Prefix    *string `url:"prefix,omitempty"`
+	Cursor    *string `url:"cursor,omitempty"`
+	Limit     *int    `url:"limit,omitempty"`
+}
+
+type dataStoreV1EntryKeys struct {
+	Keys []struct {
+		Scope string `json:"scope"`
+		Key   string `json:"key"`
+	} `json:"keys"`
+	NextPageCursor string `json:"nextPageCursor"`
+}
+
+// ListEntries will fetch a list of entry keys for a specific data store under a specific universe.
+// Only the Path and ID of the returned entries are set, and NextPageToken is the cursor of the next page.
+//
+// Required scopes: universe-datastores.objects:list
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#List-Entries
+//
+// [GET] /datastores/v1/universes/{universeId}/standard-datastores/datastore/entries
+func (s *DataStoreV1Service) ListEntries(ctx context.Context, universeId, dataStoreName string, opts *DataStoreV1ListEntriesOptions) (*DataStoreEntriesList, *Response, error) {
+	u, err := dataStoreV1URL(universeId, "/entries", url.Values{"datastoreName": {dataStoreName}}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := new(dataStoreV1EntryKeys)
+	resp, err := s.client.Do(ctx, req, keys)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	list := &DataStoreEntriesList{NextPageToken: keys.NextPageCursor}
+	for _, key := range keys.Keys {
+		list.DataStoreEntries = append(list.DataStoreEntries, DataStoreEntry{
+			Path:  dataStoreV1EntryPath(universeId, dataStoreName, key.Scope, key.Key),
+			ID:    key.Key,
+			State: DataStoreEntryStateActive,
+		})
+	}
+
+	return list, resp, nil
+}
+
+type DataStoreV1EntryOptions struct {
+	Scope *string `url:"scope,omitempty"`
+}
+
+// GetEntry will fetch an entry for a specific data store under a specific universe.
+// The value is checked against the content-md5 header, and ErrContentMD5Mismatch is returned if it does not match.
+// ErrContentMD5Missing is returned if the header is not set.
+//
+// Required scopes: universe-datastores.objects:read
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#Get-Entry
+//
+// [GET] /datastores/v1/universes/{universeId}/standard-datastores/datastore/entries/entry
+func (s *DataStoreV1Service) GetEntry(ctx context.Context, universeId, dataStoreName, entryKey string, opts *DataStoreV1EntryOptions) (*DataStoreEntry, *Response, error) {
+	u, err := dataStoreV1URL(universeId, "/entries/entry", url.Values{"datastoreName": {dataStoreName}, "entryKey": {entryKey}}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, resp, err := s.doRaw(ctx, req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	scope := "global"
+	if opts != nil && opts.Scope != nil {
+		scope = *opts.Scope
+	}
+
+	entry, err := dataStoreV1Entry(resp, body, universeId, dataStoreName, scope, entryKey)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return entry, resp, nil
+}
+
+type DataStoreV1EntrySetOptions struct {
+	Scope           *string `url:"scope,omitempty"`
+	MatchVersion    *string `url:"matchVersion,omitempty"`
+	ExclusiveCreate *bool   `url:"exclusiveCreate,omitempty"`
+}
+
+type DataStoreV1EntrySet struct {
+	Value      any
+	Users      []string
+	Attributes map[string]any
+}
+
+type DataStoreV1EntryVersion struct {
+	Version           string `json:"version"`
+	Deleted           bool   `json:"deleted"`
+	ContentLength     int    `json:"contentLength"`
+	CreatedTime       string `json:"createdTime"`
+	ObjectCreatedTime string `json:"objectCreatedTime"`
+}
+
+// SetEntry will create or overwrite an entry for a specific data store under a specific universe.
+// The content-md5 header is computed from the encoded value, so Roblox rejects the write if the value was corrupted in transit.
+//
+// Users are user IDs, and a "users/" prefix is removed.
+//
+// Required scopes: universe-datastores.objects:create, universe-datastores.objects:update
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#Set-Entry
+//
+// [POST] /datastores/v1/universes/{universeId}/standard-datastores/datastore/entries/entry
+func (s *DataStoreV1Service) SetEntry(ctx context.Context, universeId, dataStoreName, entryKey string, data DataStoreV1EntrySet, opts *DataStoreV1EntrySetOptions) (*DataStoreV1EntryVersion, *Response, error) {
+	u, err := dataStoreV1URL(universeId, "/entries/entry", url.Values{"datastoreName": {dataStoreName}, "entryKey": {entryKey}}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := json.Marshal(data.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-MD5", contentMD5(body))
+
+	if err := setDataStoreV1Metadata(req, data.Users, data.Attributes); err != nil {
+		return nil, nil, err
+	}
+
+	version := new(DataStoreV1EntryVersion)
+	resp, err := s.client.Do(ctx, req, version)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return version, resp, nil
+}
+
+// DeleteEntry will delete an entry for a specific data store under a specific universe.
+//
+// Required scopes: universe-datastores.objects:delete
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#Delete-Entry
+//
+// [DELETE] /datastores/v1/universes/{universeId}/standard-datastores/datastore/entries/entry
+func (s *DataStoreV1Service) DeleteEntry(ctx context.Context, universeId, dataStoreName, entryKey string, opts *DataStoreV1EntryOptions) (*Response, error) {
+	u, err := dataStoreV1URL(universeId, "/entries/entry", url.Values{"datastoreName": {dataStoreName}, "entryKey": {entryKey}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+type DataStoreV1EntryIncrementOptions struct {
+	Scope       *string `url:"scope,omitempty"`
+	IncrementBy int     `url:"incrementBy"`
+}
+
+// IncrementEntry will increment the value of an entry for a specific data store under a specific universe.
+// The returned value is checked against the content-md5 header.
+//
+// Required scopes: universe-datastores.objects:create, universe-datastores.objects:update
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#Increment-Entry
+//
+// [POST] /datastores/v1/universes/{universeId}/standard-datastores/datastore/entries/entry/increment
+func (s *DataStoreV1Service) IncrementEntry(ctx context.Context, universeId, dataStoreName, entryKey string, users []string, attributes map[string]any, opts *DataStoreV1EntryIncrementOptions) (*DataStoreEntry, *Response, error) {
+	u, err := dataStoreV1URL(universeId, "/entries/entry/increment", url.Values{"datastoreName": {dataStoreName}, "entryKey": {entryKey}}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := setDataStoreV1Metadata(req, users, attributes); err != nil {
+		return nil, nil, err
+	}
+
+	body, resp, err := s.doRaw(ctx, req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	scope := "global"
+	if opts != nil && opts.Scope != nil {
+		scope = *opts.Scope
+	}
+
+	entry, err := dataStoreV1Entry(resp, body, universeId, dataStoreName, scope, entryKey)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return entry, resp, nil
+}
+
+type DataStoreV1ListEntryVersionsOptions struct {
+	Scope     *string `url:"scope,omitempty"`
+	Cursor    *string `url:"cursor,omitempty"`
+	StartTime *string `url:"startTime,omitempty"`
+	EndTime   *string `url:"endTime,omitempty"`
+	SortOrder *string `url:"sortOrder,omitempty"`
+	Limit     *int    `url:"limit,omitempty"`
+}
+
+type DataStoreV1EntryVersions struct {
+	Versions       []DataStoreV1EntryVersion `json:"versions"`
+	NextPageCursor string                    `json:"nextPageCursor"`
+}
+
+// ListEntryVersions will fetch a list of versions for an entry for a specific data store under a specific universe.
+//
+// Required scopes: universe-datastores.versions:list
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#List-Entry-Versions
+//
+// [GET] /datastores/v1/universes/{universeId}/standard-datastores/datastore/entries/entry/versions
+func (s *DataStoreV1Service) ListEntryVersions(ctx context.Context, universeId, dataStoreName, entryKey string, opts *DataStoreV1ListEntryVersionsOptions) (*DataStoreV1EntryVersions, *Response, error) {
+	u, err := dataStoreV1URL(universeId, "/entries/entry/versions", url.Values{"datastoreName": {dataStoreName}, "entryKey": {entryKey}}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	versions := new(DataStoreV1EntryVersions)
+	resp, err := s.client.Do(ctx, req, versions)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return versions, resp, nil
+}
+
+// GetEntryVersion will fetch a specific version of an entry for a specific data store under a specific universe.
+// The value is checked against the content-md5 header.
+//
+// Required scopes: universe-datastores.versions:read
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/DataStore#Get-Entry-Version
+//
+// [GET] /datastores/v1/universes/{universeId}/standard-datastores/datastore/entries/entry/versions/version
+func (s *DataStoreV1Service) GetEntryVersion(ctx context.Context, universeId, dataStoreName, entryKey, versionId string, opts *DataStoreV1EntryOptions) (*DataStoreEntry, *Response, error) {
+	u, err := dataStoreV1URL(universeId, "/entries/entry/versions/version", url.Values{"datastoreName": {dataStoreName}, "entryKey": {entryKey}, "versionId": {versionId}}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, resp, err := s.doRaw(ctx, req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	scope := "global"
+	if opts != nil && opts.Scope != nil {
+		scope = *opts.Scope
+	}
+
+	entry, err := dataStoreV1Entry(resp, body, universeId, dataStoreName, scope, entryKey)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return entry, resp, nil
+}
+
+// doRaw will send the request and return the raw body, since entry values need to be hashed exactly as they were sent.
+func (s *DataStoreV1Service) doRaw(ctx context.Context, req *http.Request) ([]byte, *Response, error) {
+	resp, err := s.client.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	response := &Response{Response: resp}
+	if err := checkResponse(response); err != nil {
+		return nil, response, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, response, err
+	}
+
+	return body, response, nil
+}
+
+// dataStoreV1URL will build the URL of a v1 endpoint, combining the required query parameters with the options.
+func dataStoreV1URL(universeId, path string, params url.Values, opts any) (string, error) {
+	u := fmt.Sprintf("/datastores/v1/universes/%s/standard-datastores/datastore%s", universeId, path)
+
+	if opts != nil {
+		values, err := query.Values(opts)
+		if err != nil {
+			return "", err
+		}
+		for key, value := range values {
+			params[key] = value
+		}
+	}
+
+	return u + "?" + params.Encode(), nil
+}
+
+func dataStoreV1EntryPath(universeId, dataStoreName, scope, entryKey string) string {
+	return fmt.Sprintf("universes/%s/data-stores/%s/scopes/%s/entries/%s", universeId, dataStoreName, scope, entryKey)
+}
+
+// dataStoreV1Entry will verify the value against its content-md5 and map it onto a DataStoreEntry.
+func dataStoreV1Entry(resp *Response, body []byte, universeId, dataStoreName, scope, entryKey string) (*DataStoreEntry, error) {
+	expected := resp.Header.Get("Content-MD5")
+	if expected == "" {
+		return nil, ErrContentMD5Missing
+	}
+	if expected != contentMD5(body) {
+		return nil, ErrContentMD5Mismatch
+	}
+
+	entry := &DataStoreEntry{
+		Path:                 dataStoreV1EntryPath(universeId, dataStoreName, scope, entryKey),
+		CreateTime:           resp.Header.Get("Roblox-Entry-Created-Time"),
+		RevisionID:           resp.Header.Get("Roblox-Entry-Version"),
+		RevisionCreationTime: resp.Header.Get("Roblox-Entry-Version-Created-Time"),
+		State:                DataStoreEntryStateActive,
+		Etag:                 resp.Header.Get("Roblox-Entry-Version"),
+		ID:                   entryKey,
+	}
+
+	if err := json.Unmarshal(body, &entry.Value); err != nil {
+		return nil, err
+	}
+
+	if userIds := resp.Header.Get("Roblox-Entry-Userids"); userIds != "" {
+		var ids []int64
+		if err := json.Unmarshal([]byte(userIds), &ids); err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			entry.Users = append(entry.Users, strconv.FormatInt(id, 10))
+		}
+	}
+
+	if attributes := resp.Header.Get("Roblox-Entry-Attributes"); attributes != "" {
+		if err := json.Unmarshal([]byte(attributes), &entry.Attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	return entry, nil
+}
+
+// setDataStoreV1Metadata will set the user IDs and attributes headers of a write.
+func setDataStoreV1Metadata(req *http.Request, users []string, attributes map[string]any) error {
+	if len(users) > 0 {
+		ids := make([]int64, len(users))
+		for i, user := range users {
+			id, err := strconv.ParseInt(strings.TrimPrefix(user, "users/"), 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid user id %q: %w", user, err)
+			}
+			ids[i] = id
+		}
+
+		b, err := json.Marshal(ids)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Roblox-Entry-Userids", string(b))
+	}
+
+	if len(attributes) > 0 {
+		b, err := json.Marshal(attributes)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Roblox-Entry-Attributes", string(b))
+	}
+
+	return nil
+}
+
+// contentMD5 will return the base64 encoded MD5 checksum of the body.
+func contentMD5(body []byte) string {
+	sum := md5.Sum(body)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,92 @@
+package filter
+
+import "strconv"
+
+// DataStoreEntriesBuilder builds filters for ListDataStoreEntriesOptions.Filter and the filter of ListDataStores.
+type DataStoreEntriesBuilder struct {
+	expression
+}
+
+// DataStoreEntries will create a filter for data stores and data store entries.
+//
+// Roblox only supports filtering by the prefix of the ID, for example:
+//
+//	id.startsWith("Player_")
+func DataStoreEntries() *DataStoreEntriesBuilder {
+	return &DataStoreEntriesBuilder{}
+}
+
+// IDStartsWith will only match IDs that start with the prefix.
+func (b *DataStoreEntriesBuilder) IDStartsWith(prefix string) *DataStoreEntriesBuilder {
+	if prefix == "" {
+		b.fail("filter: IDStartsWith: prefix must not be empty")
+	}
+	if len(b.clauses) > 0 {
+		b.fail("filter: IDStartsWith: only one prefix is supported")
+	}
+
+	b.add("id.startsWith(" + quote(prefix) + ")")
+	return b
+}
+
+func (b *DataStoreEntriesBuilder) Build() (string, error) {
+	return b.build(" && ")
+}
+
+func (b *DataStoreEntriesBuilder) String() string {
+	return b.render(" && ")
+}
+
+// OrderedDataStoreEntriesBuilder builds filters for ListOrderedDataStoreEntriesOptions.Filter.
+type OrderedDataStoreEntriesBuilder struct {
+	expression
+	min, max *int
+}
+
+// OrderedDataStoreEntries will create a filter for ordered data store entries, for example:
+//
+//	entry >= 10 && entry <= 50
+func OrderedDataStoreEntries() *OrderedDataStoreEntriesBuilder {
+	return &OrderedDataStoreEntriesBuilder{}
+}
+
+// ValueAtLeast will only match entries with a value greater than or equal to the minimum.
+func (b *OrderedDataStoreEntriesBuilder) ValueAtLeast(min int) *OrderedDataStoreEntriesBuilder {
+	if b.min != nil {
+		b.fail("filter: ValueAtLeast: a minimum value was already set")
+	}
+	if b.max != nil && min > *b.max {
+		b.fail("filter: ValueAtLeast: minimum %d is greater than maximum %d", min, *b.max)
+	}
+
+	b.min = &min
+	b.add("entry >= " + strconv.Itoa(min))
+	return b
+}
+
+// ValueAtMost will only match entries with a value less than or equal to the maximum.
+func (b *OrderedDataStoreEntriesBuilder) ValueAtMost(max int) *OrderedDataStoreEntriesBuilder {
+	if b.max != nil {
+		b.fail("filter: ValueAtMost: a maximum value was already set")
+	}
+	if b.min != nil && max < *b.min {
+		b.fail("filter: ValueAtMost: maximum %d is less than minimum %d", max, *b.min)
+	}
+
+	b.max = &max
+	b.add("entry <= " + strconv.Itoa(max))
+	return b
+}
+
+// ValueBetween will only match entries with a value between the minimum and maximum, inclusive.
+func (b *OrderedDataStoreEntriesBuilder) ValueBetween(min, max int) *OrderedDataStoreEntriesBuilder {
+	return b.ValueAtLeast(min).ValueAtMost(max)
+}
+
+func (b *OrderedDataStoreEntriesBuilder) Build() (string, error) {
+	return b.build(" && ")
+}
+
+func (b *OrderedDataStoreEntriesBuilder) String() string {
+	return b.render(" && ")
+}
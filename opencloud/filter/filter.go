@@ -0,0 +1,74 @@
+// Package filter contains typed builders for the filter strings accepted by the List methods of the opencloud package.
+// It does not depend on the opencloud package, so the helpers of the opencloud package build their filters with it too.
+//
+// Each builder renders a filter string in the syntax expected by the resource, and validates it before it is sent:
+//
+//	f, err := filter.DataStoreEntries().IDStartsWith("Player_").Build()
+//	if err != nil {
+//		return err
+//	}
+//
+//	entries, _, err := client.DataAndMemoryStore.ListDataStoreEntries(ctx, universeId, dataStoreId, nil, &opencloud.ListDataStoreEntriesOptions{
+//		Filter: opencloud.Pointer(f),
+//	})
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Builder is implemented by every filter builder.
+type Builder interface {
+	// Build will render the filter string, or return every validation error found while building it.
+	Build() (string, error)
+}
+
+var numericIDRegex = regexp.MustCompile(`^\d+$`)
+
+// expression collects the clauses and validation errors of a builder.
+type expression struct {
+	clauses []string
+	errs    []error
+}
+
+func (e *expression) add(clause string) {
+	e.clauses = append(e.clauses, clause)
+}
+
+func (e *expression) fail(format string, args ...any) {
+	e.errs = append(e.errs, fmt.Errorf(format, args...))
+}
+
+func (e *expression) render(separator string) string {
+	return strings.Join(e.clauses, separator)
+}
+
+func (e *expression) build(separator string) (string, error) {
+	if len(e.errs) > 0 {
+		return "", errors.Join(e.errs...)
+	}
+	if len(e.clauses) == 0 {
+		return "", errors.New("filter: no conditions were added")
+	}
+
+	return e.render(separator), nil
+}
+
+// quote will render a string literal that is safe to embed in a filter.
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+// quoteList will render a list literal of strings, such as ["a", "b"].
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote(value)
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,117 @@
+package filter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var groupRoleRegex = regexp.MustCompile(`^groups/\d+/roles/\d+$`)
+
+// userPath will convert a user ID into a resource path, leaving paths untouched.
+func (e *expression) userPath(method, user string) string {
+	if numericIDRegex.MatchString(user) {
+		return "users/" + user
+	}
+	if !strings.HasPrefix(user, "users/") || !numericIDRegex.MatchString(strings.TrimPrefix(user, "users/")) {
+		e.fail("filter: %s: %q is not a user ID or users/{id} path", method, user)
+	}
+
+	return user
+}
+
+// GroupMembershipsBuilder builds filters for the OptionsWithFilter of ListGroupMemberships.
+type GroupMembershipsBuilder struct {
+	expression
+}
+
+// GroupMemberships will create a filter for group memberships, for example:
+//
+//	role == "groups/7/roles/99513316"
+func GroupMemberships() *GroupMembershipsBuilder {
+	return &GroupMembershipsBuilder{}
+}
+
+// User will only match the membership of a user. The user can be an ID or a users/{id} path.
+func (b *GroupMembershipsBuilder) User(user string) *GroupMembershipsBuilder {
+	b.add("user == " + quote(b.userPath("User", user)))
+	return b
+}
+
+// UserIn will only match the memberships of the users. The users can be IDs or users/{id} paths.
+func (b *GroupMembershipsBuilder) UserIn(users ...string) *GroupMembershipsBuilder {
+	if len(users) == 0 {
+		b.fail("filter: UserIn: at least one user is required")
+	}
+
+	paths := make([]string, len(users))
+	for i, user := range users {
+		paths[i] = b.userPath("UserIn", user)
+	}
+
+	b.add("user in " + quoteList(paths))
+	return b
+}
+
+// Role will only match memberships with the role. The role must be a groups/{id}/roles/{id} path.
+func (b *GroupMembershipsBuilder) Role(role string) *GroupMembershipsBuilder {
+	b.validateRole("Role", role)
+	b.add("role == " + quote(role))
+	return b
+}
+
+// RoleIn will only match memberships with one of the roles. The roles must be groups/{id}/roles/{id} paths.
+func (b *GroupMembershipsBuilder) RoleIn(roles ...string) *GroupMembershipsBuilder {
+	if len(roles) == 0 {
+		b.fail("filter: RoleIn: at least one role is required")
+	}
+	for _, role := range roles {
+		b.validateRole("RoleIn", role)
+	}
+
+	b.add("role in " + quoteList(roles))
+	return b
+}
+
+func (b *GroupMembershipsBuilder) validateRole(method, role string) {
+	if !groupRoleRegex.MatchString(role) {
+		b.fail("filter: %s: %q is not a groups/{id}/roles/{id} path", method, role)
+	}
+}
+
+func (b *GroupMembershipsBuilder) Build() (string, error) {
+	return b.build(" && ")
+}
+
+func (b *GroupMembershipsBuilder) String() string {
+	return b.render(" && ")
+}
+
+// GroupJoinRequestsBuilder builds filters for the OptionsWithFilter of ListGroupJoinRequests.
+type GroupJoinRequestsBuilder struct {
+	expression
+}
+
+// GroupJoinRequests will create a filter for group join requests, for example:
+//
+//	user == "users/156"
+func GroupJoinRequests() *GroupJoinRequestsBuilder {
+	return &GroupJoinRequestsBuilder{}
+}
+
+// User will only match the join request of a user. The user can be an ID or a users/{id} path.
+func (b *GroupJoinRequestsBuilder) User(user string) *GroupJoinRequestsBuilder {
+	if len(b.clauses) > 0 {
+		b.fail("filter: User: only one user is supported")
+	}
+
+	b.add("user == " + quote(b.userPath("User", user)))
+	return b
+}
+
+func (b *GroupJoinRequestsBuilder) Build() (string, error) {
+	return b.build(" && ")
+}
+
+func (b *GroupJoinRequestsBuilder) String() string {
+	return b.render(" && ")
+}
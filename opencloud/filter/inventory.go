@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// InventoryBuilder builds filters for the OptionsWithFilter of ListInventoryItems.
+type InventoryBuilder struct {
+	expression
+	fields map[string]bool
+}
+
+// Inventory will create a filter for inventory items.
+// Unlike the other filters, this is a list of fields separated by semicolons, for example:
+//
+//	gamePassIds=123,456;onlyCollectibles=true
+func Inventory() *InventoryBuilder {
+	return &InventoryBuilder{fields: make(map[string]bool)}
+}
+
+func (b *InventoryBuilder) field(name, value string) *InventoryBuilder {
+	if b.fields[name] {
+		b.fail("filter: %s was already set", name)
+	}
+
+	b.fields[name] = true
+	b.add(name + "=" + value)
+	return b
+}
+
+func (b *InventoryBuilder) ids(name string, ids []string) *InventoryBuilder {
+	if len(ids) == 0 {
+		b.fail("filter: %s: at least one ID is required", name)
+	}
+	for _, id := range ids {
+		if !numericIDRegex.MatchString(id) {
+			b.fail("filter: %s: %q is not a numeric ID", name, id)
+		}
+	}
+
+	return b.field(name, strings.Join(ids, ","))
+}
+
+// AssetIds will only match the assets with the IDs.
+func (b *InventoryBuilder) AssetIds(ids ...string) *InventoryBuilder {
+	return b.ids("assetIds", ids)
+}
+
+// BadgeIds will only match the badges with the IDs.
+func (b *InventoryBuilder) BadgeIds(ids ...string) *InventoryBuilder {
+	return b.ids("badgeIds", ids)
+}
+
+// GamePassIds will only match the game passes with the IDs.
+func (b *InventoryBuilder) GamePassIds(ids ...string) *InventoryBuilder {
+	return b.ids("gamePassIds", ids)
+}
+
+// PrivateServerIds will only match the private servers with the IDs.
+func (b *InventoryBuilder) PrivateServerIds(ids ...string) *InventoryBuilder {
+	return b.ids("privateServerIds", ids)
+}
+
+// AssetTypes will only match assets with one of the types, such as string(opencloud.InventoryItemAssetTypeModel).
+// The types are plain strings, since this package can not depend on the opencloud package that uses it.
+func (b *InventoryBuilder) AssetTypes(types ...string) *InventoryBuilder {
+	if len(types) == 0 {
+		b.fail("filter: inventoryItemAssetTypes: at least one type is required")
+	}
+
+	values := make([]string, len(types))
+	for i, assetType := range types {
+		if assetType == "" || assetType == "INVENTORY_ITEM_ASSET_TYPE_UNSPECIFIED" {
+			b.fail("filter: inventoryItemAssetTypes: %q is not a valid asset type", assetType)
+		}
+		values[i] = assetType
+	}
+
+	return b.field("inventoryItemAssetTypes", strings.Join(values, ","))
+}
+
+// Badges will include or exclude every badge.
+func (b *InventoryBuilder) Badges(include bool) *InventoryBuilder {
+	return b.field("badges", strconv.FormatBool(include))
+}
+
+// GamePasses will include or exclude every game pass.
+func (b *InventoryBuilder) GamePasses(include bool) *InventoryBuilder {
+	return b.field("gamePasses", strconv.FormatBool(include))
+}
+
+// PrivateServers will include or exclude every private server.
+func (b *InventoryBuilder) PrivateServers(include bool) *InventoryBuilder {
+	return b.field("privateServers", strconv.FormatBool(include))
+}
+
+// OnlyCollectibles will only match collectible items.
+func (b *InventoryBuilder) OnlyCollectibles() *InventoryBuilder {
+	return b.field("onlyCollectibles", "true")
+}
+
+func (b *InventoryBuilder) Build() (string, error) {
+	return b.build(";")
+}
+
+func (b *InventoryBuilder) String() string {
+	return b.render(";")
+}
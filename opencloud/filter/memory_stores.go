@@ -0,0 +1,145 @@
+package filter
+
+import (
+	"math"
+	"strconv"
+)
+
+// SortedMapBuilder builds filters for MemoryStoreSortedMapItemListOptions.Filter.
+type SortedMapBuilder struct {
+	expression
+	lower, upper any
+}
+
+// SortedMap will create a filter for memory store sorted map items.
+// Sort keys can be strings or numbers, and bounds are exclusive, for example:
+//
+//	id > "Player_1" && sortKey > 10 && sortKey < 50
+func SortedMap() *SortedMapBuilder {
+	return &SortedMapBuilder{}
+}
+
+// IDAfter will only match items with an ID that sorts after the ID.
+func (b *SortedMapBuilder) IDAfter(id string) *SortedMapBuilder {
+	b.add("id > " + quote(id))
+	return b
+}
+
+// IDBefore will only match items with an ID that sorts before the ID.
+func (b *SortedMapBuilder) IDBefore(id string) *SortedMapBuilder {
+	b.add("id < " + quote(id))
+	return b
+}
+
+// SortKeyGreaterThan will only match items with a sort key greater than the value.
+// The value must be a string or a number.
+func (b *SortedMapBuilder) SortKeyGreaterThan(value any) *SortedMapBuilder {
+	literal, ok := b.sortKey("SortKeyGreaterThan", value)
+	if b.lower != nil {
+		b.fail("filter: SortKeyGreaterThan: a lower bound was already set")
+	}
+	if ok && b.upper != nil && !sortKeyLess(value, b.upper) {
+		b.fail("filter: SortKeyGreaterThan: lower bound %v is not less than upper bound %v", value, b.upper)
+	}
+
+	b.lower = value
+	b.add("sortKey > " + literal)
+	return b
+}
+
+// SortKeyLessThan will only match items with a sort key less than the value.
+// The value must be a string or a number.
+func (b *SortedMapBuilder) SortKeyLessThan(value any) *SortedMapBuilder {
+	literal, ok := b.sortKey("SortKeyLessThan", value)
+	if b.upper != nil {
+		b.fail("filter: SortKeyLessThan: an upper bound was already set")
+	}
+	if ok && b.lower != nil && !sortKeyLess(b.lower, value) {
+		b.fail("filter: SortKeyLessThan: upper bound %v is not greater than lower bound %v", value, b.lower)
+	}
+
+	b.upper = value
+	b.add("sortKey < " + literal)
+	return b
+}
+
+// SortKeyBetween will only match items with a sort key between the bounds, exclusive.
+func (b *SortedMapBuilder) SortKeyBetween(lower, upper any) *SortedMapBuilder {
+	return b.SortKeyGreaterThan(lower).SortKeyLessThan(upper)
+}
+
+// sortKey will render a sort key literal, recording an error if the value is not a string or number.
+func (b *SortedMapBuilder) sortKey(method string, value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return quote(v), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		f, _ := toFloat(v)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			b.fail("filter: %s: sort key must be a finite number, got %v", method, f)
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	default:
+		b.fail("filter: %s: sort key must be a string or a number, got %T", method, value)
+		return "", false
+	}
+}
+
+// sortKeyLess will report if a sorts before b. Numbers always sort before strings.
+func sortKeyLess(a, b any) bool {
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+
+	switch {
+	case aIsString && bIsString:
+		return as < bs
+	case aIsString:
+		return false
+	case bIsString:
+		return true
+	}
+
+	af, aOk := toFloat(a)
+	bf, bOk := toFloat(b)
+	return aOk && bOk && af < bf
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func (b *SortedMapBuilder) Build() (string, error) {
+	return b.build(" && ")
+}
+
+func (b *SortedMapBuilder) String() string {
+	return b.render(" && ")
+}
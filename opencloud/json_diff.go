@@ -0,0 +1,117 @@
+package opencloud
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type JSONPatchOp string
+
+const (
+	JSONPatchOpAdd     JSONPatchOp = "add"
+	JSONPatchOpRemove  JSONPatchOp = "remove"
+	JSONPatchOpReplace JSONPatchOp = "replace"
+)
+
+// JSONPatchOperation is a single change between two JSON values, in the format of RFC 6902.
+// Previous is not part of the RFC, and holds the value that was removed or replaced.
+type JSONPatchOperation struct {
+	Op       JSONPatchOp `json:"op"`
+	Path     string      `json:"path"`
+	Value    any         `json:"value"`
+	Previous any         `json:"previous"`
+}
+
+// MarshalJSON will always include the value of add and replace operations and the previous value of remove and replace
+// operations, even when it is null, false, 0 or an empty string, and leave out the fields that do not apply to the operation.
+func (o JSONPatchOperation) MarshalJSON() ([]byte, error) {
+	operation := struct {
+		Op       JSONPatchOp `json:"op"`
+		Path     string      `json:"path"`
+		Value    *any        `json:"value,omitempty"`
+		Previous *any        `json:"previous,omitempty"`
+	}{Op: o.Op, Path: o.Path}
+
+	if o.Op != JSONPatchOpRemove {
+		operation.Value = &o.Value
+	}
+	if o.Op != JSONPatchOpAdd {
+		operation.Previous = &o.Previous
+	}
+
+	return json.Marshal(operation)
+}
+
+// DiffJSON will compute the operations that turn one decoded JSON value into another.
+// Objects are compared by key and arrays by index, paths are JSON pointers as described in RFC 6901.
+func DiffJSON(from, to any) []JSONPatchOperation {
+	var ops []JSONPatchOperation
+	diffJSON("", from, to, &ops)
+	return ops
+}
+
+func jsonPointerEscape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func diffJSON(path string, from, to any, ops *[]JSONPatchOperation) {
+	switch from := from.(type) {
+	case map[string]any:
+		to, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(from)+len(to))
+		for key := range from {
+			keys = append(keys, key)
+		}
+		for key := range to {
+			if _, ok := from[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			fromValue, inFrom := from[key]
+			toValue, inTo := to[key]
+			keyPath := path + "/" + jsonPointerEscape(key)
+
+			switch {
+			case !inTo:
+				*ops = append(*ops, JSONPatchOperation{Op: JSONPatchOpRemove, Path: keyPath, Previous: fromValue})
+			case !inFrom:
+				*ops = append(*ops, JSONPatchOperation{Op: JSONPatchOpAdd, Path: keyPath, Value: toValue})
+			default:
+				diffJSON(keyPath, fromValue, toValue, ops)
+			}
+		}
+		return
+
+	case []any:
+		to, ok := to.([]any)
+		if !ok {
+			break
+		}
+
+		for i := range min(len(from), len(to)) {
+			diffJSON(path+"/"+strconv.Itoa(i), from[i], to[i], ops)
+		}
+		for i := len(from); i < len(to); i++ {
+			*ops = append(*ops, JSONPatchOperation{Op: JSONPatchOpAdd, Path: path + "/" + strconv.Itoa(i), Value: to[i]})
+		}
+		// Trailing elements are removed from the end, so the indexes stay valid while the operations are applied in order.
+		for i := len(from) - 1; i >= len(to); i-- {
+			*ops = append(*ops, JSONPatchOperation{Op: JSONPatchOpRemove, Path: path + "/" + strconv.Itoa(i), Previous: from[i]})
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*ops = append(*ops, JSONPatchOperation{Op: JSONPatchOpReplace, Path: path, Value: to, Previous: from})
+	}
+}
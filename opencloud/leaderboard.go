@@ -0,0 +1,298 @@
+package opencloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/typical-developers/goblox/opencloud/filter"
+)
+
+// ErrLeaderboardEntryNotFound is returned when a user does not have an entry on the leaderboard.
+var ErrLeaderboardEntryNotFound = errors.New("leaderboard entry not found")
+
+type LeaderboardOrder string
+
+const (
+	// LeaderboardOrderDescending ranks the highest value first.
+	LeaderboardOrderDescending LeaderboardOrder = "DESCENDING"
+	// LeaderboardOrderAscending ranks the lowest value first.
+	LeaderboardOrderAscending LeaderboardOrder = "ASCENDING"
+)
+
+type LeaderboardSubmitPolicy string
+
+const (
+	// LeaderboardSubmitMax keeps the highest score that was submitted.
+	LeaderboardSubmitMax LeaderboardSubmitPolicy = "MAX"
+	// LeaderboardSubmitMin keeps the lowest score that was submitted.
+	LeaderboardSubmitMin LeaderboardSubmitPolicy = "MIN"
+	// LeaderboardSubmitSum adds the score to the current score with IncrementOrderedDataStoreEntry.
+	LeaderboardSubmitSum LeaderboardSubmitPolicy = "SUM"
+	// LeaderboardSubmitReplace always replaces the current score.
+	LeaderboardSubmitReplace LeaderboardSubmitPolicy = "REPLACE"
+)
+
+type LeaderboardEntry struct {
+	// Rank uses standard competition ranking, so tied values share the same rank (1, 2, 2, 4).
+	Rank  int    `json:"rank"`
+	ID    string `json:"id"`
+	Value int    `json:"value"`
+}
+
+// Leaderboard ranks the entries of an ordered data store scope, where each entry ID is a user ID.
+type Leaderboard struct {
+	service            *DataAndMemoryStoreService
+	universeId         string
+	orderedDataStoreId string
+	scopeId            string
+
+	// Order defaults to LeaderboardOrderDescending.
+	Order LeaderboardOrder
+	// PageSize is the amount of entries listed per request.
+	PageSize int
+}
+
+// NewLeaderboard will create a leaderboard for a specific ordered data store scope under a specific universe.
+func (s *DataAndMemoryStoreService) NewLeaderboard(universeId, orderedDataStoreId, scopeId string) *Leaderboard {
+	return &Leaderboard{
+		service:            s,
+		universeId:         universeId,
+		orderedDataStoreId: orderedDataStoreId,
+		scopeId:            scopeId,
+		Order:              LeaderboardOrderDescending,
+		PageSize:           100,
+	}
+}
+
+func (l *Leaderboard) orderBy() string {
+	if l.Order == LeaderboardOrderAscending {
+		return "value"
+	}
+
+	return "value desc"
+}
+
+// betterFilter will return a filter that matches every entry ranked strictly above the value.
+func (l *Leaderboard) betterFilter(value int) string {
+	if l.Order == LeaderboardOrderAscending {
+		return filter.OrderedDataStoreEntries().ValueAtMost(value - 1).String()
+	}
+
+	return filter.OrderedDataStoreEntries().ValueAtLeast(value + 1).String()
+}
+
+// atLeastAsGoodFilter will return a filter that matches every entry ranked at or above the value.
+func (l *Leaderboard) atLeastAsGoodFilter(value int) string {
+	if l.Order == LeaderboardOrderAscending {
+		return filter.OrderedDataStoreEntries().ValueAtMost(value).String()
+	}
+
+	return filter.OrderedDataStoreEntries().ValueAtLeast(value).String()
+}
+
+// list will page through the ordered entries, calling fn until it returns false.
+func (l *Leaderboard) list(ctx context.Context, entryFilter *string, fn func(entry OrderedDataStoreEntry) bool) error {
+	opts := &ListOrderedDataStoreEntriesOptions{
+		MaxPageSize: Pointer(l.PageSize),
+		OrderBy:     Pointer(l.orderBy()),
+		Filter:      entryFilter,
+	}
+
+	for {
+		entries, resp, err := l.service.ListOrderedDataStoreEntries(ctx, l.universeId, l.orderedDataStoreId, l.scopeId, opts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries.OrderedDataStoreEntries {
+			if !fn(entry) {
+				return nil
+			}
+		}
+
+		if entries.NextPageToken == "" {
+			return nil
+		}
+		opts.PageToken = Pointer(entries.NextPageToken)
+	}
+}
+
+// countBetter will count the entries ranked strictly above the value.
+func (l *Leaderboard) countBetter(ctx context.Context, value int) (int, error) {
+	count := 0
+	err := l.list(ctx, Pointer(l.betterFilter(value)), func(OrderedDataStoreEntry) bool {
+		count++
+		return true
+	})
+
+	return count, err
+}
+
+// Top will return the first n entries of the leaderboard.
+func (l *Leaderboard) Top(ctx context.Context, n int) ([]LeaderboardEntry, error) {
+	return l.Page(ctx, 0, n)
+}
+
+// Page will return size entries of the leaderboard, starting at the zero-based offset.
+func (l *Leaderboard) Page(ctx context.Context, offset, size int) ([]LeaderboardEntry, error) {
+	if offset < 0 || size <= 0 {
+		return nil, nil
+	}
+
+	var page []LeaderboardEntry
+	position := 0
+	err := l.list(ctx, nil, func(entry OrderedDataStoreEntry) bool {
+		if position >= offset {
+			page = append(page, LeaderboardEntry{ID: entry.ID, Value: entry.Value})
+		}
+
+		position++
+		return len(page) < size
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.rank(ctx, page, offset); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// rank will assign ranks to consecutive entries that start at the zero-based position.
+// The first entry is ranked by counting the entries above it, so pages that start in the middle of a tie are ranked the same as the full leaderboard.
+func (l *Leaderboard) rank(ctx context.Context, entries []LeaderboardEntry, position int) error {
+	for i := range entries {
+		switch {
+		case i > 0 && entries[i].Value == entries[i-1].Value:
+			entries[i].Rank = entries[i-1].Rank
+		case i > 0 || position == 0:
+			entries[i].Rank = position + i + 1
+		default:
+			better, err := l.countBetter(ctx, entries[i].Value)
+			if err != nil {
+				return err
+			}
+
+			entries[i].Rank = better + 1
+		}
+	}
+
+	return nil
+}
+
+// RankOf will return the ranked entry of a user.
+//
+// ErrLeaderboardEntryNotFound is returned if the user does not have an entry.
+func (l *Leaderboard) RankOf(ctx context.Context, userId string) (*LeaderboardEntry, error) {
+	entry, resp, err := l.service.GetOrderedDataStoreEntry(ctx, l.universeId, l.orderedDataStoreId, l.scopeId, userId)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isStatus(err, http.StatusNotFound) {
+		return nil, ErrLeaderboardEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	better, err := l.countBetter(ctx, entry.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LeaderboardEntry{Rank: better + 1, ID: entry.ID, Value: entry.Value}, nil
+}
+
+// AroundUser will return the entries within radius positions above and below a user, including the user.
+//
+// ErrLeaderboardEntryNotFound is returned if the user does not have an entry.
+func (l *Leaderboard) AroundUser(ctx context.Context, userId string, radius int) ([]LeaderboardEntry, error) {
+	entry, resp, err := l.service.GetOrderedDataStoreEntry(ctx, l.universeId, l.orderedDataStoreId, l.scopeId, userId)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isStatus(err, http.StatusNotFound) {
+		return nil, ErrLeaderboardEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The position of the user is found by walking the entries ranked at or above them, since tied entries can be listed before the user.
+	position, found := 0, false
+	err = l.list(ctx, Pointer(l.atLeastAsGoodFilter(entry.Value)), func(listed OrderedDataStoreEntry) bool {
+		if listed.ID == userId {
+			found = true
+			return false
+		}
+
+		position++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrLeaderboardEntryNotFound
+	}
+
+	offset := max(position-radius, 0)
+	return l.Page(ctx, offset, position-offset+radius+1)
+}
+
+// Submit will record a score for a user with the submit policy, returning the entry after the score was applied.
+//
+// Ordered data stores do not support etags, so the MAX and MIN policies can lose a concurrent write.
+// Use LeaderboardSubmitSum when multiple writers can submit for the same user at once.
+func (l *Leaderboard) Submit(ctx context.Context, userId string, score int, policy LeaderboardSubmitPolicy) (*OrderedDataStoreEntry, error) {
+	switch policy {
+	case LeaderboardSubmitSum:
+		entry, resp, err := l.service.IncrementOrderedDataStoreEntry(ctx, l.universeId, l.orderedDataStoreId, l.scopeId, userId, OrderedDataStoreEntryIncrement{
+			Amount: Pointer(score),
+		})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+
+		return entry, err
+	case LeaderboardSubmitReplace:
+		return l.replace(ctx, userId, score)
+	case LeaderboardSubmitMax, LeaderboardSubmitMin:
+		current, resp, err := l.service.GetOrderedDataStoreEntry(ctx, l.universeId, l.orderedDataStoreId, l.scopeId, userId)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if isStatus(err, http.StatusNotFound) {
+			return l.replace(ctx, userId, score)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if (policy == LeaderboardSubmitMax && score <= current.Value) || (policy == LeaderboardSubmitMin && score >= current.Value) {
+			return current, nil
+		}
+
+		return l.replace(ctx, userId, score)
+	default:
+		return nil, fmt.Errorf("unknown leaderboard submit policy %q", policy)
+	}
+}
+
+func (l *Leaderboard) replace(ctx context.Context, userId string, score int) (*OrderedDataStoreEntry, error) {
+	entry, resp, err := l.service.UpdateOrderedDataStoreEntry(ctx, l.universeId, l.orderedDataStoreId, l.scopeId, userId, OrderedDataStoreEntryUpdate{
+		Value: Pointer(score),
+	}, &OrderedDataStoreEntryUpdateOpts{AllowMissing: Pointer(true)})
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	return entry, err
+}
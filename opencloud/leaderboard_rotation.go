@@ -0,0 +1,346 @@
+package opencloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type LeaderboardRotationStage string
+
+const (
+	// LeaderboardRotationStageExporting means writers were switched to the new scope and the previous scope is being exported.
+	LeaderboardRotationStageExporting LeaderboardRotationStage = "EXPORTING"
+	// LeaderboardRotationStageRewarding means the previous scope was exported and the rewards are being awarded.
+	LeaderboardRotationStageRewarding LeaderboardRotationStage = "REWARDING"
+	// LeaderboardRotationStageDone means the last rotation completed.
+	LeaderboardRotationStageDone LeaderboardRotationStage = "DONE"
+)
+
+// LeaderboardRotationState is stored in a data store entry so writers can find the current scope and a crashed rotation can be resumed.
+type LeaderboardRotationState struct {
+	CurrentScope  string                   `json:"currentScope"`
+	RotatingScope string                   `json:"rotatingScope,omitempty"`
+	Stage         LeaderboardRotationStage `json:"stage"`
+	UpdateTime    string                   `json:"updateTime"`
+}
+
+// LeaderboardArchive is the full ordering of a season.
+type LeaderboardArchive struct {
+	OrderedDataStore string             `json:"orderedDataStore"`
+	Scope            string             `json:"scope"`
+	ExportTime       string             `json:"exportTime"`
+	Entries          []LeaderboardEntry `json:"entries"`
+}
+
+// LeaderboardRewardFunc is called with the top entries of a season that was rotated out.
+// It can be called more than once for the same season if a rotation crashes while rewarding, so it should be idempotent for the scope.
+type LeaderboardRewardFunc func(ctx context.Context, scope string, top []LeaderboardEntry) error
+
+// LeaderboardRotation will rotate an ordered data store to a new scope every season.
+type LeaderboardRotation struct {
+	service            *DataAndMemoryStoreService
+	universeId         string
+	orderedDataStoreId string
+
+	// StateDataStore is the data store that holds the rotation state.
+	StateDataStore string
+	// StateKey defaults to "rotation-{orderedDataStoreId}".
+	StateKey string
+
+	// ArchiveDataStore will store the archive of each season in an entry named "{orderedDataStoreId}-{scope}", when set.
+	// Data store entries have a size limit, so very large leaderboards should use ArchiveDir instead.
+	ArchiveDataStore *string
+	// ArchiveDir will store the archive of each season in a local JSON file, when set.
+	ArchiveDir *string
+
+	// TopN is the amount of entries passed to Reward.
+	TopN int
+	// Reward is called with the top entries of the archive. A rotation that crashed while rewarding reads the stored archive back,
+	// or exports the frozen scope again when neither ArchiveDataStore nor ArchiveDir is set.
+	Reward LeaderboardRewardFunc
+
+	// Order defaults to LeaderboardOrderDescending.
+	Order LeaderboardOrder
+	// ScopeName will name the scope of the season that contains the time.
+	// Defaults to "season-{year}-{week}" using ISO weeks, for example "season-2026-42".
+	ScopeName func(t time.Time) string
+}
+
+// NewLeaderboardRotation will create a rotation for a specific ordered data store under a specific universe.
+// The state is stored in the state data store, which must be a regular data store.
+func (s *DataAndMemoryStoreService) NewLeaderboardRotation(universeId, orderedDataStoreId, stateDataStoreId string) *LeaderboardRotation {
+	return &LeaderboardRotation{
+		service:            s,
+		universeId:         universeId,
+		orderedDataStoreId: orderedDataStoreId,
+		StateDataStore:     stateDataStoreId,
+		StateKey:           fmt.Sprintf("rotation-%s", orderedDataStoreId),
+		Order:              LeaderboardOrderDescending,
+		ScopeName:          WeeklySeasonScope,
+	}
+}
+
+// WeeklySeasonScope will name a scope after the ISO week of the time, for example "season-2026-42".
+func WeeklySeasonScope(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("season-%d-%02d", year, week)
+}
+
+// state will fetch the rotation state and its etag. A nil state is returned if the rotation has never run.
+func (r *LeaderboardRotation) state(ctx context.Context) (*LeaderboardRotationState, string, error) {
+	entry, resp, err := r.service.GetDataStoreEntry(withoutDataStoreCache(ctx), r.universeId, r.StateDataStore, nil, r.StateKey)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isStatus(err, http.StatusNotFound) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	state := new(LeaderboardRotationState)
+	if err := decodeValue(entry.Value, state); err != nil {
+		return nil, "", err
+	}
+
+	return state, entry.Etag, nil
+}
+
+// saveState will write the state with etag protection, so two rotations can not run at the same time.
+func (r *LeaderboardRotation) saveState(ctx context.Context, state *LeaderboardRotationState, etag string) (string, error) {
+	state.UpdateTime = time.Now().UTC().Format(time.RFC3339Nano)
+
+	var value any = state
+	var entry *DataStoreEntry
+	var resp *Response
+	var err error
+	if etag == "" {
+		entry, resp, err = r.service.CreateDataStoreEntry(ctx, r.universeId, r.StateDataStore, nil, DataStoreEntryCreate{
+			Value: &value,
+		}, &DataStoreEntryCreateOptions{ID: Pointer(r.StateKey)})
+	} else {
+		entry, resp, err = r.service.UpdateDataStoreEntry(ctx, r.universeId, r.StateDataStore, nil, r.StateKey, DataStoreEntryUpdate{
+			Etag:  Pointer(etag),
+			Value: &value,
+		}, nil)
+	}
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return entry.Etag, nil
+}
+
+// CurrentScope will return the scope that writers should submit scores to.
+// If the rotation has never run, the scope of the current season is returned.
+func (r *LeaderboardRotation) CurrentScope(ctx context.Context) (string, error) {
+	state, _, err := r.state(ctx)
+	if err != nil {
+		return "", err
+	}
+	if state == nil {
+		return r.ScopeName(time.Now()), nil
+	}
+
+	return state.CurrentScope, nil
+}
+
+// Leaderboard will return a leaderboard for the current scope.
+func (r *LeaderboardRotation) Leaderboard(ctx context.Context) (*Leaderboard, error) {
+	scope, err := r.CurrentScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard := r.service.NewLeaderboard(r.universeId, r.orderedDataStoreId, scope)
+	leaderboard.Order = r.Order
+	return leaderboard, nil
+}
+
+// Rotate will move writers to the scope of the season that contains the time, then export and reward the previous scope.
+//
+// Every stage is recorded in the state entry, so calling Rotate again after a crash will resume the unfinished rotation.
+// Calling Rotate when the current scope already belongs to the season is a no-op.
+func (r *LeaderboardRotation) Rotate(ctx context.Context, now time.Time) (*LeaderboardRotationState, error) {
+	state, etag, err := r.state(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	newScope := r.ScopeName(now)
+	if state == nil {
+		// There is nothing to rotate out the first time, so writers are only pointed at the current season.
+		state = &LeaderboardRotationState{CurrentScope: newScope, Stage: LeaderboardRotationStageDone}
+		if _, err := r.saveState(ctx, state, ""); err != nil {
+			return nil, err
+		}
+
+		return state, nil
+	}
+
+	if state.Stage == LeaderboardRotationStageDone {
+		if state.CurrentScope == newScope {
+			return state, nil
+		}
+
+		// Switching writers to the new scope first freezes the previous scope before it is exported.
+		state.RotatingScope = state.CurrentScope
+		state.CurrentScope = newScope
+		state.Stage = LeaderboardRotationStageExporting
+		if etag, err = r.saveState(ctx, state, etag); err != nil {
+			return nil, err
+		}
+	}
+
+	var archive *LeaderboardArchive
+	if state.Stage == LeaderboardRotationStageExporting {
+		if archive, err = r.export(ctx, state.RotatingScope); err != nil {
+			return state, err
+		}
+
+		state.Stage = LeaderboardRotationStageRewarding
+		if etag, err = r.saveState(ctx, state, etag); err != nil {
+			return state, err
+		}
+	}
+
+	if state.Stage == LeaderboardRotationStageRewarding {
+		if r.Reward != nil && r.TopN > 0 {
+			// Rewards are computed from the archive instead of the scope, since late writes can still change the scope.
+			// A resumed rotation reuses the stored archive, and only exports the scope again if it was not stored.
+			if archive == nil {
+				if archive, err = r.export(ctx, state.RotatingScope); err != nil {
+					return state, err
+				}
+			}
+
+			top := archive.Entries[:min(r.TopN, len(archive.Entries))]
+			if err := r.Reward(ctx, state.RotatingScope, top); err != nil {
+				return state, err
+			}
+		}
+
+		state.RotatingScope = ""
+		state.Stage = LeaderboardRotationStageDone
+		if _, err = r.saveState(ctx, state, etag); err != nil {
+			return state, err
+		}
+	}
+
+	return state, nil
+}
+
+func (r *LeaderboardRotation) archiveKey(scope string) string {
+	return fmt.Sprintf("%s-%s", r.orderedDataStoreId, scope)
+}
+
+// storedArchive will read the archive of a scope from the archive data store or directory, or return nil if it was not stored.
+func (r *LeaderboardRotation) storedArchive(ctx context.Context, scope string) (*LeaderboardArchive, error) {
+	archiveKey := r.archiveKey(scope)
+
+	if r.ArchiveDataStore != nil {
+		entry, resp, err := r.service.GetDataStoreEntry(ctx, r.universeId, *r.ArchiveDataStore, nil, archiveKey)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err == nil {
+			archive := new(LeaderboardArchive)
+			if err := decodeValue(entry.Value, archive); err != nil {
+				return nil, err
+			}
+
+			return archive, nil
+		}
+		if !isStatus(err, http.StatusNotFound) {
+			return nil, err
+		}
+	}
+
+	if r.ArchiveDir != nil {
+		b, err := os.ReadFile(filepath.Join(*r.ArchiveDir, archiveKey+".json"))
+		if err == nil {
+			archive := new(LeaderboardArchive)
+			if err := json.Unmarshal(b, archive); err != nil {
+				return nil, err
+			}
+
+			return archive, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// export will archive the full ordering of a scope. An archive that was already written is reused instead of being written again.
+func (r *LeaderboardRotation) export(ctx context.Context, scope string) (*LeaderboardArchive, error) {
+	archiveKey := r.archiveKey(scope)
+
+	stored, err := r.storedArchive(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		return stored, r.writeLocalArchive(archiveKey, stored)
+	}
+
+	leaderboard := r.service.NewLeaderboard(r.universeId, r.orderedDataStoreId, scope)
+	leaderboard.Order = r.Order
+
+	archive := &LeaderboardArchive{
+		OrderedDataStore: r.orderedDataStoreId,
+		Scope:            scope,
+		ExportTime:       time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	err = leaderboard.list(ctx, nil, func(entry OrderedDataStoreEntry) bool {
+		archive.Entries = append(archive.Entries, LeaderboardEntry{ID: entry.ID, Value: entry.Value})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := leaderboard.rank(ctx, archive.Entries, 0); err != nil {
+		return nil, err
+	}
+
+	if r.ArchiveDataStore != nil {
+		var value any = archive
+		_, resp, err := r.service.CreateDataStoreEntry(ctx, r.universeId, *r.ArchiveDataStore, nil, DataStoreEntryCreate{
+			Value: &value,
+		}, &DataStoreEntryCreateOptions{ID: Pointer(archiveKey)})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return archive, r.writeLocalArchive(archiveKey, archive)
+}
+
+func (r *LeaderboardRotation) writeLocalArchive(archiveKey string, archive *LeaderboardArchive) error {
+	if r.ArchiveDir == nil {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(*r.ArchiveDir, archiveKey+".json"), b, 0o644)
+}
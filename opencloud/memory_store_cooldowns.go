@@ -0,0 +1,204 @@
+package opencloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrCooldownContention is returned when a cooldown could not be updated because it kept being changed concurrently.
+var ErrCooldownContention = errors.New("cooldown was changed concurrently too often")
+
+// cooldownValue is stored in the sorted map item of a cooldown. Game servers can read and write the same format
+// to share the limit, with the item ID "{action}:{userId}" and the window and hits as unix milliseconds.
+type cooldownValue struct {
+	UserID string  `json:"userId"`
+	Action string  `json:"action"`
+	Limit  int     `json:"limit"`
+	Window int64   `json:"windowMs"`
+	Hits   []int64 `json:"hits"`
+}
+
+type CooldownResult struct {
+	Allowed bool
+	// Count is the amount of hits within the window, including this one if it was allowed.
+	Count int
+	// RetryAfter is how long until the next hit is allowed, or 0 if it is allowed right away.
+	RetryAfter time.Duration
+}
+
+type ActiveCooldown struct {
+	UserID string
+	Action string
+	// Count is the amount of hits within the window.
+	Count int
+	Limit int
+	// Blocked is set when the limit is reached, and no hits are allowed until the oldest of them leaves the window.
+	Blocked bool
+	// ExpireTime is when the newest hit leaves the window, and the cooldown is removed.
+	ExpireTime time.Time
+}
+
+// Cooldowns is a table of per-user rate limits stored in a memory store sorted map, so the limits are shared across servers.
+// The sort key of every item is the time its newest hit leaves the window, in unix seconds, which is also when the item expires.
+type Cooldowns struct {
+	service     *DataAndMemoryStoreService
+	universeId  string
+	sortedMapId string
+
+	// MaxConflictRetries is the amount of times a hit is retried when the cooldown was changed concurrently. Defaults to 5.
+	MaxConflictRetries int
+}
+
+// NewCooldowns will create a cooldown table in a sorted map under a specific universe.
+func (s *DataAndMemoryStoreService) NewCooldowns(universeId, sortedMapId string) *Cooldowns {
+	return &Cooldowns{
+		service:            s,
+		universeId:         universeId,
+		sortedMapId:        sortedMapId,
+		MaxConflictRetries: 5,
+	}
+}
+
+func cooldownKey(userId, action string) string {
+	return fmt.Sprintf("%s:%s", action, userId)
+}
+
+// Try will record a hit for the user and action if there was no other hit within the window.
+func (c *Cooldowns) Try(ctx context.Context, userId, action string, window time.Duration) (*CooldownResult, error) {
+	return c.TryN(ctx, userId, action, 1, window)
+}
+
+// TryN will record a hit for the user and action if there were less than limit hits within the sliding window.
+func (c *Cooldowns) TryN(ctx context.Context, userId, action string, limit int, window time.Duration) (*CooldownResult, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("TryN: limit must be at least 1, got %d", limit)
+	}
+	key := cooldownKey(userId, action)
+
+	for range max(c.MaxConflictRetries, 0) + 1 {
+		item, resp, err := c.service.GetMemoryStoreSortedMapItem(ctx, c.universeId, c.sortedMapId, key)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil && !isStatus(err, http.StatusNotFound) {
+			return nil, err
+		}
+		exists := err == nil
+
+		now := time.Now()
+		value := cooldownValue{UserID: userId, Action: action}
+		if exists {
+			if err := decodeValue(item.Value, &value); err != nil {
+				return nil, err
+			}
+		}
+		value.Limit = limit
+		value.Window = window.Milliseconds()
+
+		// Hits that left the window are dropped, which makes the window slide.
+		cutoff := now.Add(-window).UnixMilli()
+		hits := value.Hits[:0]
+		for _, hit := range value.Hits {
+			if hit > cutoff {
+				hits = append(hits, hit)
+			}
+		}
+		value.Hits = hits
+
+		if len(value.Hits) >= limit {
+			oldest := time.UnixMilli(value.Hits[len(value.Hits)-limit])
+			return &CooldownResult{Count: len(value.Hits), RetryAfter: oldest.Add(window).Sub(now)}, nil
+		}
+		value.Hits = append(value.Hits, now.UnixMilli())
+
+		var data any = value
+		sortKey := float64(now.Add(window).Unix())
+		if exists {
+			_, resp, err = c.service.UpdateMemoryStoreSortedMapItem(ctx, c.universeId, c.sortedMapId, key, MemoryStoreSortedMapItemUpdate{
+				Etag:           Pointer(item.Etag),
+				Value:          &data,
+				TTL:            memoryStoreTTL(window),
+				NumericSortKey: &sortKey,
+			}, nil)
+		} else {
+			_, resp, err = c.service.CreateMemoryStoreSortedMapItem(ctx, c.universeId, c.sortedMapId, MemoryStoreSortedMapItemCreate{
+				Value:          &data,
+				TTL:            memoryStoreTTL(window),
+				NumericSortKey: &sortKey,
+			}, &MemoryStoreSortedMapItemCreateOptions{ID: key})
+		}
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if isConflict(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &CooldownResult{Allowed: true, Count: len(value.Hits)}, nil
+	}
+
+	return nil, ErrCooldownContention
+}
+
+// Reset will remove the cooldown of the user and action.
+func (c *Cooldowns) Reset(ctx context.Context, userId, action string) error {
+	resp, err := c.service.DeleteMemoryStoreSortedMapItem(ctx, c.universeId, c.sortedMapId, cooldownKey(userId, action))
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isStatus(err, http.StatusNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// Active will list every cooldown that still has hits within its window, soonest to expire first.
+func (c *Cooldowns) Active(ctx context.Context) ([]ActiveCooldown, error) {
+	now := time.Now()
+	items := NewSortedMap[cooldownValue](c.service, c.universeId, c.sortedMapId).
+		Range(NewNumericSortKey(float64(now.Unix()-1)), SortKey{}).
+		Ascending(ctx)
+
+	var active []ActiveCooldown
+	for item, err := range items {
+		if err != nil {
+			return active, err
+		}
+
+		cooldown := ActiveCooldown{
+			UserID: item.Value.UserID,
+			Action: item.Value.Action,
+			Limit:  item.Value.Limit,
+		}
+
+		// Items written without a window only have the sort key, so every hit is counted until it expires.
+		window := time.Duration(item.Value.Window) * time.Millisecond
+		if window > 0 && len(item.Value.Hits) > 0 {
+			cutoff := now.Add(-window).UnixMilli()
+			for _, hit := range item.Value.Hits {
+				if hit > cutoff {
+					cooldown.Count++
+				}
+			}
+			cooldown.ExpireTime = time.UnixMilli(item.Value.Hits[len(item.Value.Hits)-1]).Add(window)
+		} else if seconds, ok := item.SortKey.NumericValue(); ok {
+			cooldown.Count = len(item.Value.Hits)
+			cooldown.ExpireTime = time.Unix(int64(seconds), 0)
+		}
+		if cooldown.Count == 0 || !cooldown.ExpireTime.After(now) {
+			continue
+		}
+
+		cooldown.Blocked = cooldown.Count >= cooldown.Limit
+		active = append(active, cooldown)
+	}
+
+	return active, nil
+}
@@ -0,0 +1,358 @@
+package opencloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// OperationFailedError is returned when a long-running operation finished with an error.
+type OperationFailedError struct {
+	Operation *Operation
+}
+
+func (e *OperationFailedError) Error() string {
+	b, _ := json.Marshal(e.Operation.Error)
+	return fmt.Sprintf("operation %s failed: %s", e.Operation.Path, b)
+}
+
+// MemoryStoreArchive is a copy of memory store sorted maps, queues and hash maps, keyed by their ID.
+type MemoryStoreArchive struct {
+	Universe   string                                `json:"universe"`
+	ExportTime string                                `json:"exportTime"`
+	SortedMaps map[string][]MemoryStoreSortedMapItem `json:"sortedMaps,omitempty"`
+	Queues     map[string][]MemoryStoreQueueItem     `json:"queues,omitempty"`
+	HashMaps   map[string][]MemoryStoreHashMapItem   `json:"hashMaps,omitempty"`
+}
+
+// ReadMemoryStoreArchive will decode an archive that was written with WriteMemoryStoreArchive.
+func ReadMemoryStoreArchive(r io.Reader) (*MemoryStoreArchive, error) {
+	archive := new(MemoryStoreArchive)
+	if err := json.NewDecoder(r).Decode(archive); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// WriteMemoryStoreArchive will encode the archive as JSON.
+func WriteMemoryStoreArchive(w io.Writer, archive *MemoryStoreArchive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(archive)
+}
+
+type MemoryStoreExportOptions struct {
+	SortedMaps []string
+	Queues     []string
+	HashMaps   []string
+	// InvisibilityWindow is how long exported queue items are hidden from other readers. Reading is the only way to see
+	// the items of a queue, so they have to stay hidden until the export and flush are done. Defaults to 5 minutes.
+	// Items that become visible again during the export are only exported once.
+	InvisibilityWindow time.Duration
+}
+
+// ExportMemoryStore will copy the named sorted maps, queues and hash maps of a universe into an archive.
+func (s *DataAndMemoryStoreService) ExportMemoryStore(ctx context.Context, universeId string, opts MemoryStoreExportOptions) (*MemoryStoreArchive, error) {
+	archive := &MemoryStoreArchive{
+		Universe:   universeId,
+		ExportTime: time.Now().UTC().Format(time.RFC3339Nano),
+		SortedMaps: make(map[string][]MemoryStoreSortedMapItem),
+		Queues:     make(map[string][]MemoryStoreQueueItem),
+		HashMaps:   make(map[string][]MemoryStoreHashMapItem),
+	}
+
+	for _, sortedMapId := range opts.SortedMaps {
+		listOpts := &MemoryStoreSortedMapItemListOptions{MaxPageSize: Pointer(100)}
+		items := []MemoryStoreSortedMapItem{}
+		for {
+			list, resp, err := s.ListMemoryStoreSortedMapItems(ctx, universeId, sortedMapId, listOpts)
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				return archive, fmt.Errorf("exporting sorted map %s: %w", sortedMapId, err)
+			}
+
+			items = append(items, list.MemoryStoreSortedMapItems...)
+			if list.NextPageToken == "" {
+				break
+			}
+			listOpts.PageToken = Pointer(list.NextPageToken)
+		}
+		archive.SortedMaps[sortedMapId] = items
+	}
+
+	window := opts.InvisibilityWindow
+	if window == 0 {
+		window = 5 * time.Minute
+	}
+	for _, queueId := range opts.Queues {
+		items := []MemoryStoreQueueItem{}
+		seen := make(map[string]bool)
+		for {
+			read, resp, err := s.ReadMemoryStoreQueueItems(ctx, universeId, queueId, &MemoryStoreQueueItemsOptions{
+				Count:              Pointer(200),
+				InvisibilityWindow: memoryStoreTTL(window),
+			})
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				return archive, fmt.Errorf("exporting queue %s: %w", queueId, err)
+			}
+
+			// Once the window passes, items are read again. A read without new items means every item was exported.
+			added := 0
+			for _, item := range read.Items {
+				if !seen[item.ID] {
+					seen[item.ID] = true
+					items = append(items, item)
+					added++
+				}
+			}
+			if added == 0 {
+				break
+			}
+		}
+		archive.Queues[queueId] = items
+	}
+
+	for _, hashMapId := range opts.HashMaps {
+		listOpts := &MemoryStoreHashMapItemListOptions{MaxPageSize: Pointer(100)}
+		items := []MemoryStoreHashMapItem{}
+		for {
+			list, resp, err := s.ListMemoryStoreHashMapItems(ctx, universeId, hashMapId, listOpts)
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				return archive, fmt.Errorf("exporting hash map %s: %w", hashMapId, err)
+			}
+
+			items = append(items, list.MemoryStoreHashMapItems...)
+			if list.NextPageToken == "" {
+				break
+			}
+			listOpts.PageToken = Pointer(list.NextPageToken)
+		}
+		archive.HashMaps[hashMapId] = items
+	}
+
+	return archive, nil
+}
+
+type MemoryStoreFlushOptions struct {
+	// Export will copy the named sorted maps, queues and hash maps before flushing. The memory store is not flushed if the export fails.
+	Export *MemoryStoreExportOptions
+	// ArchivePath will write the export to a local JSON file before flushing.
+	ArchivePath string
+	// PollInterval is how often the flush operation is checked. Defaults to 2 seconds.
+	PollInterval time.Duration
+}
+
+// FlushMemoryStoreAndWait will flush the memory store of a universe and wait until the flush has finished,
+// optionally exporting data first so it can be restored with RestoreMemoryStoreArchive.
+//
+// An OperationFailedError is returned if the flush finished with an error.
+func (s *DataAndMemoryStoreService) FlushMemoryStoreAndWait(ctx context.Context, universeId string, opts *MemoryStoreFlushOptions) (*Operation, *MemoryStoreArchive, error) {
+	if opts == nil {
+		opts = &MemoryStoreFlushOptions{}
+	}
+	pollInterval := opts.PollInterval
+	if pollInterval == 0 {
+		pollInterval = 2 * time.Second
+	}
+
+	var archive *MemoryStoreArchive
+	if opts.Export != nil {
+		var err error
+		archive, err = s.ExportMemoryStore(ctx, universeId, *opts.Export)
+		if err != nil {
+			return nil, archive, err
+		}
+
+		if opts.ArchivePath != "" {
+			f, err := os.Create(opts.ArchivePath)
+			if err != nil {
+				return nil, archive, err
+			}
+			err = WriteMemoryStoreArchive(f, archive)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return nil, archive, err
+			}
+		}
+	}
+
+	operation, resp, err := s.FlushMemoryStore(ctx, universeId)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, archive, err
+	}
+
+	for !operation.Done {
+		select {
+		case <-ctx.Done():
+			return operation, archive, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		next, resp, err := s.GetMemoryStoreOperation(ctx, operation.Path)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return operation, archive, err
+		}
+		operation = next
+	}
+
+	if operation.Error != nil {
+		return operation, archive, &OperationFailedError{Operation: operation}
+	}
+
+	return operation, archive, nil
+}
+
+// GetMemoryStoreOperation will fetch a long-running memory store operation, such as a flush, by its path.
+//
+// Required scopes: universe.memory-store:flush
+//
+// Roblox Opencloud API Docs: https://create.roblox.com/docs/en-us/cloud/reference/Operation
+//
+// [GET] /cloud/v2/universes/{universe_id}/memory-store/operations/{operation_id}
+func (s *DataAndMemoryStoreService) GetMemoryStoreOperation(ctx context.Context, operationPath string) (*Operation, *Response, error) {
+	u := fmt.Sprintf("/cloud/v2/%s", operationPath)
+
+	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	operation := new(Operation)
+	resp, err := s.client.Do(ctx, req, operation)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return operation, resp, nil
+}
+
+type MemoryStoreRestoreReport struct {
+	SortedMapItems int
+	QueueItems     int
+	HashMapItems   int
+	// Expired are items that were not restored because their expire time passed.
+	Expired int
+}
+
+// remainingTTL will return the time left until the expire time, and whether it is long enough to restore the item.
+func remainingTTL(expireTime string) (time.Duration, bool, error) {
+	expire, err := time.Parse(time.RFC3339Nano, expireTime)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid expire time %q: %w", expireTime, err)
+	}
+
+	ttl := time.Until(expire)
+	return ttl, ttl >= time.Second, nil
+}
+
+// RestoreMemoryStoreArchive will write the items of an archive back to the memory store of a universe, with their remaining TTL.
+// Sorted map and hash map items replace existing items with the same ID, queue items are enqueued again.
+func (s *DataAndMemoryStoreService) RestoreMemoryStoreArchive(ctx context.Context, universeId string, archive *MemoryStoreArchive) (*MemoryStoreRestoreReport, error) {
+	report := new(MemoryStoreRestoreReport)
+
+	for sortedMapId, items := range archive.SortedMaps {
+		for _, item := range items {
+			ttl, ok, err := remainingTTL(item.ExpireTime)
+			if err != nil {
+				return report, fmt.Errorf("restoring sorted map %s item %s: %w", sortedMapId, item.ID, err)
+			}
+			if !ok {
+				report.Expired++
+				continue
+			}
+
+			value := item.Value
+			_, resp, err := s.UpdateMemoryStoreSortedMapItem(ctx, universeId, sortedMapId, item.ID, MemoryStoreSortedMapItemUpdate{
+				Value:          &value,
+				TTL:            memoryStoreTTL(ttl),
+				StringSortKey:  item.StringSortKey,
+				NumericSortKey: item.NumericSortKey,
+			}, &MemoryStoreSortedMapItemUpdateOpts{AllowMissing: Pointer(true)})
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				return report, fmt.Errorf("restoring sorted map %s item %s: %w", sortedMapId, item.ID, err)
+			}
+			report.SortedMapItems++
+		}
+	}
+
+	for queueId, items := range archive.Queues {
+		for _, item := range items {
+			ttl, ok, err := remainingTTL(item.ExpireTime)
+			if err != nil {
+				return report, fmt.Errorf("restoring queue %s item %s: %w", queueId, item.ID, err)
+			}
+			if !ok {
+				report.Expired++
+				continue
+			}
+
+			data := item.Data
+			_, resp, err := s.CreateMemoryStoreQueueItem(ctx, universeId, queueId, MemoryStoreQueueItemCreate{
+				Data:     &data,
+				Priority: Pointer(item.Priority),
+				TTL:      memoryStoreTTL(ttl),
+			})
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				return report, fmt.Errorf("restoring queue %s item %s: %w", queueId, item.ID, err)
+			}
+			report.QueueItems++
+		}
+	}
+
+	for hashMapId, items := range archive.HashMaps {
+		for _, item := range items {
+			ttl, ok, err := remainingTTL(item.ExpireTime)
+			if err != nil {
+				return report, fmt.Errorf("restoring hash map %s item %s: %w", hashMapId, item.ID, err)
+			}
+			if !ok {
+				report.Expired++
+				continue
+			}
+
+			value := item.Value
+			_, resp, err := s.UpdateMemoryStoreHashMapItem(ctx, universeId, hashMapId, item.ID, MemoryStoreHashMapItemUpdate{
+				Value: &value,
+				TTL:   memoryStoreTTL(ttl),
+			}, &MemoryStoreHashMapItemUpdateOpts{AllowMissing: Pointer(true)})
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				return report, fmt.Errorf("restoring hash map %s item %s: %w", hashMapId, item.ID, err)
+			}
+			report.HashMapItems++
+		}
+	}
+
+	return report, nil
+}
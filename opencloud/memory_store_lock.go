@@ -0,0 +1,338 @@
+package opencloud
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLockHeld is returned by TryAcquireLock when the lock is held by someone else.
+	ErrLockHeld = errors.New("lock is held by another holder")
+	// ErrLockLost is returned when the lease of a lock expired or was taken over before it was renewed or released.
+	ErrLockLost = errors.New("lock lease was lost")
+)
+
+// lockFenceSuffix is appended to the key of a lock for the item that stores its latest fencing token.
+const lockFenceSuffix = ".fence"
+
+type LockOptions struct {
+	// Holder identifies the owner of the lock, such as a hostname. Defaults to a random ID.
+	Holder string
+	// RetryInterval is how often AcquireLock tries to take a held lock. Defaults to 1 second.
+	RetryInterval time.Duration
+	// AutoRenew will renew the lease in the background at a third of the TTL until the lock is released.
+	AutoRenew bool
+	// FencingDataStore will store the fencing token counter in an entry of this data store, which never expires.
+	// Without it, the counter is a memory store item that is kept for the maximum TTL from the last acquire,
+	// so the tokens start at 1 again when the lock is not acquired for 45 days or the item is evicted.
+	FencingDataStore *string
+}
+
+// lockValue is stored in the sorted map item of a lock.
+type lockValue struct {
+	Holder      string `json:"holder"`
+	Token       int64  `json:"token"`
+	AcquireTime string `json:"acquireTime"`
+}
+
+// Lock is a lease on a key of a memory store sorted map. Only one holder can have the lease at a time,
+// and the lease expires after its TTL unless it is renewed.
+type Lock struct {
+	service     *DataAndMemoryStoreService
+	universeId  string
+	sortedMapId string
+	key         string
+	ttl         time.Duration
+
+	// Holder is the owner of the lock.
+	Holder string
+	// Token is a fencing token that is higher for every holder that acquires the lock. Pass it along with writes to
+	// resources protected by the lock, so writes from a previous holder that lost its lease can be rejected.
+	// See LockOptions.FencingDataStore for when the tokens can start over.
+	Token int64
+
+	mu         sync.Mutex
+	etag       string
+	expireTime time.Time
+	lost       chan struct{}
+	lostOnce   sync.Once
+	stop       context.CancelFunc
+}
+
+// AcquireLock will take the lock on a key of a sorted map, waiting until it is released or expires when it is held.
+func (s *DataAndMemoryStoreService) AcquireLock(ctx context.Context, universeId, sortedMapId, key string, ttl time.Duration, opts *LockOptions) (*Lock, error) {
+	if opts == nil {
+		opts = &LockOptions{}
+	}
+	retryInterval := opts.RetryInterval
+	if retryInterval == 0 {
+		retryInterval = time.Second
+	}
+
+	for {
+		lock, err := s.TryAcquireLock(ctx, universeId, sortedMapId, key, ttl, opts)
+		if !errors.Is(err, ErrLockHeld) {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// TryAcquireLock will take the lock on a key of a sorted map, or return ErrLockHeld when it is held.
+func (s *DataAndMemoryStoreService) TryAcquireLock(ctx context.Context, universeId, sortedMapId, key string, ttl time.Duration, opts *LockOptions) (*Lock, error) {
+	if opts == nil {
+		opts = &LockOptions{}
+	}
+
+	holder := opts.Holder
+	if holder == "" {
+		random := make([]byte, 8)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		holder = hex.EncodeToString(random)
+	}
+
+	lock := &Lock{
+		service:     s,
+		universeId:  universeId,
+		sortedMapId: sortedMapId,
+		key:         key,
+		ttl:         ttl,
+		Holder:      holder,
+		lost:        make(chan struct{}),
+	}
+
+	var value any = lockValue{Holder: holder, AcquireTime: time.Now().UTC().Format(time.RFC3339Nano)}
+	item, resp, err := s.CreateMemoryStoreSortedMapItem(ctx, universeId, sortedMapId, MemoryStoreSortedMapItemCreate{
+		Value: &value,
+		TTL:   memoryStoreTTL(ttl),
+	}, &MemoryStoreSortedMapItemCreateOptions{ID: key})
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isConflict(err) {
+		return nil, ErrLockHeld
+	}
+	if err != nil {
+		return nil, err
+	}
+	lock.etag = item.Etag
+	lock.expireTime = time.Now().Add(ttl)
+
+	// The fencing token is taken after the lock, so a token is never higher than the one of a later holder.
+	if opts.FencingDataStore != nil {
+		lock.Token, err = lock.nextDataStoreFencingToken(ctx, *opts.FencingDataStore)
+	} else {
+		lock.Token, err = lock.nextFencingToken(ctx)
+	}
+	if err == nil {
+		err = lock.write(ctx)
+	}
+	if err != nil {
+		_ = lock.Release(ctx)
+		return nil, err
+	}
+
+	runCtx, stop := context.WithCancel(context.Background())
+	lock.stop = stop
+	go lock.watch(runCtx, opts.AutoRenew)
+
+	return lock, nil
+}
+
+// nextDataStoreFencingToken will increment the fencing token stored in a data store entry named after the lock.
+func (l *Lock) nextDataStoreFencingToken(ctx context.Context, dataStoreId string) (int64, error) {
+	entry, resp, err := l.service.IncrementDataStoreEntry(ctx, l.universeId, dataStoreId, nil, l.key+lockFenceSuffix, DataStoreEntryIncrement{
+		Amount: Pointer(1),
+	})
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var token int64
+	if err := decodeValue(entry.Value, &token); err != nil {
+		return 0, err
+	}
+
+	return token, nil
+}
+
+// nextFencingToken will increment the fencing token stored next to the lock, keeping it for the maximum TTL.
+func (l *Lock) nextFencingToken(ctx context.Context) (int64, error) {
+	fenceKey := l.key + lockFenceSuffix
+
+	for {
+		item, resp, err := l.service.GetMemoryStoreSortedMapItem(ctx, l.universeId, l.sortedMapId, fenceKey)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+
+		switch {
+		case isStatus(err, http.StatusNotFound):
+			var value any = int64(1)
+			_, resp, err := l.service.CreateMemoryStoreSortedMapItem(ctx, l.universeId, l.sortedMapId, MemoryStoreSortedMapItemCreate{
+				Value: &value,
+				TTL:   memoryStoreTTL(MaxMemoryStoreItemTTL),
+			}, &MemoryStoreSortedMapItemCreateOptions{ID: fenceKey})
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if isConflict(err) {
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			return 1, nil
+
+		case err != nil:
+			return 0, err
+		}
+
+		var token int64
+		if err := decodeValue(item.Value, &token); err != nil {
+			return 0, err
+		}
+		token++
+
+		var value any = token
+		_, resp, err = l.service.UpdateMemoryStoreSortedMapItem(ctx, l.universeId, l.sortedMapId, fenceKey, MemoryStoreSortedMapItemUpdate{
+			Etag:  Pointer(item.Etag),
+			Value: &value,
+			TTL:   memoryStoreTTL(MaxMemoryStoreItemTTL),
+		}, nil)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if isConflict(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		return token, nil
+	}
+}
+
+// write will store the lock value with the current etag, extending the lease by the TTL.
+func (l *Lock) write(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var value any = lockValue{Holder: l.Holder, Token: l.Token, AcquireTime: time.Now().UTC().Format(time.RFC3339Nano)}
+	item, resp, err := l.service.UpdateMemoryStoreSortedMapItem(ctx, l.universeId, l.sortedMapId, l.key, MemoryStoreSortedMapItemUpdate{
+		Etag:  Pointer(l.etag),
+		Value: &value,
+		TTL:   memoryStoreTTL(l.ttl),
+	}, nil)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isConflict(err) || isStatus(err, http.StatusNotFound) {
+		l.markLost()
+		return ErrLockLost
+	}
+	if err != nil {
+		return err
+	}
+
+	l.etag = item.Etag
+	l.expireTime = time.Now().Add(l.ttl)
+	return nil
+}
+
+// Renew will extend the lease by the TTL. ErrLockLost is returned if the lease expired or was taken over.
+func (l *Lock) Renew(ctx context.Context) error {
+	select {
+	case <-l.lost:
+		return ErrLockLost
+	default:
+	}
+
+	return l.write(ctx)
+}
+
+// Release will give up the lease, so the lock can be taken by another holder.
+// ErrLockLost is returned if the lease already expired or was taken over.
+func (l *Lock) Release(ctx context.Context) error {
+	if l.stop != nil {
+		l.stop()
+	}
+
+	// Writing with the etag first makes sure the lock is still ours, so the delete never removes the lock of another holder.
+	if err := l.Renew(ctx); err != nil {
+		return err
+	}
+
+	resp, err := l.service.DeleteMemoryStoreSortedMapItem(ctx, l.universeId, l.sortedMapId, l.key)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return err
+	}
+
+	l.markLost()
+	return nil
+}
+
+// Lost will return a channel that is closed once the lease is lost or released.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *Lock) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
+
+func (l *Lock) expiry() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.expireTime
+}
+
+// watch will renew the lease when auto renewal is enabled, and mark the lock as lost once the lease expires without being renewed.
+func (l *Lock) watch(ctx context.Context, autoRenew bool) {
+	for {
+		wait := time.Until(l.expiry())
+		if autoRenew {
+			wait = min(wait, l.ttl/3)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-l.lost:
+			return
+		case <-time.After(wait):
+		}
+
+		if !time.Now().Before(l.expiry()) {
+			l.markLost()
+			return
+		}
+
+		if autoRenew {
+			// Failed renewals are retried until the lease expires.
+			renewCtx, cancel := context.WithDeadline(ctx, l.expiry())
+			_ = l.Renew(renewCtx)
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package opencloud
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/typical-developers/goblox/opencloud/filter"
+)
+
+// SortKey is the sort key of a sorted map item, which is either a string, a number, or not set.
+// Items without a sort key sort before numbers, and numbers sort before strings.
+type SortKey struct {
+	str *string
+	num *float64
+}
+
+// NewStringSortKey will create a sort key from a string.
+func NewStringSortKey(s string) SortKey {
+	return SortKey{str: &s}
+}
+
+// NewNumericSortKey will create a sort key from a number, which can be fractional.
+func NewNumericSortKey(n float64) SortKey {
+	return SortKey{num: &n}
+}
+
+// IsZero will report if the sort key is not set.
+func (k SortKey) IsZero() bool {
+	return k.str == nil && k.num == nil
+}
+
+// StringValue will return the sort key if it is a string.
+func (k SortKey) StringValue() (string, bool) {
+	if k.str == nil {
+		return "", false
+	}
+
+	return *k.str, true
+}
+
+// NumericValue will return the sort key if it is a number.
+func (k SortKey) NumericValue() (float64, bool) {
+	if k.num == nil {
+		return 0, false
+	}
+
+	return *k.num, true
+}
+
+// String will render the sort key as it is written in a filter.
+func (k SortKey) String() string {
+	switch {
+	case k.str != nil:
+		return strconv.Quote(*k.str)
+	case k.num != nil:
+		return strconv.FormatFloat(*k.num, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// SortKey will return the sort key of the item.
+func (i MemoryStoreSortedMapItem) SortKey() SortKey {
+	return SortKey{str: i.StringSortKey, num: i.NumericSortKey}
+}
+
+// SortedMapItem is a sorted map item with its value decoded.
+type SortedMapItem[T any] struct {
+	ID      string
+	Value   T
+	SortKey SortKey
+	Etag    string
+}
+
+// SortedMap is a typed handle on a memory store sorted map, with values decoded into T.
+type SortedMap[T any] struct {
+	service     *DataAndMemoryStoreService
+	universeId  string
+	sortedMapId string
+
+	// PageSize is the amount of items fetched per request while iterating. Defaults to 100.
+	PageSize int
+}
+
+// NewSortedMap will create a typed handle on a sorted map under a specific universe.
+func NewSortedMap[T any](s *DataAndMemoryStoreService, universeId, sortedMapId string) *SortedMap[T] {
+	return &SortedMap[T]{
+		service:     s,
+		universeId:  universeId,
+		sortedMapId: sortedMapId,
+		PageSize:    100,
+	}
+}
+
+func decodeSortedMapItem[T any](item MemoryStoreSortedMapItem) (SortedMapItem[T], error) {
+	decoded := SortedMapItem[T]{ID: item.ID, SortKey: item.SortKey(), Etag: item.Etag}
+	if err := decodeValue(item.Value, &decoded.Value); err != nil {
+		return decoded, err
+	}
+
+	return decoded, nil
+}
+
+// Get will fetch an item of the sorted map.
+func (m *SortedMap[T]) Get(ctx context.Context, id string) (*SortedMapItem[T], error) {
+	item, resp, err := m.service.GetMemoryStoreSortedMapItem(ctx, m.universeId, m.sortedMapId, id)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := decodeSortedMapItem[T](*item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decoded, nil
+}
+
+// Set will create or replace an item of the sorted map. A ttl of 0 uses the default TTL of the service.
+func (m *SortedMap[T]) Set(ctx context.Context, id string, value T, sortKey SortKey, ttl time.Duration) (*SortedMapItem[T], error) {
+	var itemTTL *string
+	switch {
+	case ttl < 0:
+		return nil, fmt.Errorf("Set: ttl can not be negative, got %s", ttl)
+	case ttl > 0:
+		itemTTL = memoryStoreTTL(ttl)
+	}
+
+	var data any = value
+	item, resp, err := m.service.UpdateMemoryStoreSortedMapItem(ctx, m.universeId, m.sortedMapId, id, MemoryStoreSortedMapItemUpdate{
+		Value:          &data,
+		TTL:            itemTTL,
+		StringSortKey:  sortKey.str,
+		NumericSortKey: sortKey.num,
+	}, &MemoryStoreSortedMapItemUpdateOpts{AllowMissing: Pointer(true)})
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := decodeSortedMapItem[T](*item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decoded, nil
+}
+
+// SortedMapQuery narrows down the items that are iterated over.
+type SortedMapQuery[T any] struct {
+	m            *SortedMap[T]
+	lower, upper SortKey
+	afterKey     SortKey
+	afterId      *string
+}
+
+func (m *SortedMap[T]) query() *SortedMapQuery[T] {
+	return &SortedMapQuery[T]{m: m}
+}
+
+// Range will only iterate over items with a sort key between lo and hi, exclusive. A zero SortKey leaves that side unbounded.
+func (m *SortedMap[T]) Range(lo, hi SortKey) *SortedMapQuery[T] {
+	return m.query().Range(lo, hi)
+}
+
+// Seek will only iterate over items after the item with the sort key and ID, in the direction of the iteration.
+func (m *SortedMap[T]) Seek(sortKey SortKey, afterId string) *SortedMapQuery[T] {
+	return m.query().Seek(sortKey, afterId)
+}
+
+// Ascending will iterate over every item from the lowest to the highest sort key.
+func (m *SortedMap[T]) Ascending(ctx context.Context) iter.Seq2[SortedMapItem[T], error] {
+	return m.query().Ascending(ctx)
+}
+
+// Descending will iterate over every item from the highest to the lowest sort key.
+func (m *SortedMap[T]) Descending(ctx context.Context) iter.Seq2[SortedMapItem[T], error] {
+	return m.query().Descending(ctx)
+}
+
+// Range will only iterate over items with a sort key between lo and hi, exclusive. A zero SortKey leaves that side unbounded.
+func (q *SortedMapQuery[T]) Range(lo, hi SortKey) *SortedMapQuery[T] {
+	q.lower, q.upper = lo, hi
+	return q
+}
+
+// Seek will only iterate over items after the item with the sort key and ID, in the direction of the iteration,
+// so an iteration can be resumed from the last item it yielded. Items are ordered by sort key, and by ID when their
+// sort keys are equal.
+//
+// Filters only support comparing a single field, so a numeric sort key narrows down the listed items and the remaining
+// items up to the seek position are skipped while iterating. Other sort keys are only skipped while iterating.
+func (q *SortedMapQuery[T]) Seek(sortKey SortKey, afterId string) *SortedMapQuery[T] {
+	q.afterKey, q.afterId = sortKey, &afterId
+	return q
+}
+
+// Ascending will iterate over the items from the lowest to the highest sort key.
+func (q *SortedMapQuery[T]) Ascending(ctx context.Context) iter.Seq2[SortedMapItem[T], error] {
+	return q.iterate(ctx, false)
+}
+
+// Descending will iterate over the items from the highest to the lowest sort key.
+func (q *SortedMapQuery[T]) Descending(ctx context.Context) iter.Seq2[SortedMapItem[T], error] {
+	return q.iterate(ctx, true)
+}
+
+// value will return the sort key as a string or float64, or nil if it is not set.
+func (k SortKey) value() any {
+	switch {
+	case k.str != nil:
+		return *k.str
+	case k.num != nil:
+		return *k.num
+	default:
+		return nil
+	}
+}
+
+// compareSortKeys will order sort keys the way the sorted map does, with unset keys before numbers and numbers before strings.
+func compareSortKeys(a, b SortKey) int {
+	rank := func(k SortKey) int {
+		switch {
+		case k.num != nil:
+			return 1
+		case k.str != nil:
+			return 2
+		default:
+			return 0
+		}
+	}
+
+	if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+		return c
+	}
+	switch {
+	case a.num != nil:
+		return cmp.Compare(*a.num, *b.num)
+	case a.str != nil:
+		return strings.Compare(*a.str, *b.str)
+	default:
+		return 0
+	}
+}
+
+// bounds will return the exclusive sort key bounds of the query, narrowed down to a numeric seek position.
+// The bound of the seek position is the next number towards the start, so items with the same sort key are still listed.
+func (q *SortedMapQuery[T]) bounds(descending bool) (lower, upper SortKey) {
+	lower, upper = q.lower, q.upper
+
+	n, ok := q.afterKey.NumericValue()
+	if q.afterId == nil || !ok {
+		return lower, upper
+	}
+
+	if descending {
+		seek := NewNumericSortKey(math.Nextafter(n, math.Inf(1)))
+		if upper.IsZero() || compareSortKeys(seek, upper) < 0 {
+			upper = seek
+		}
+	} else {
+		seek := NewNumericSortKey(math.Nextafter(n, math.Inf(-1)))
+		if lower.IsZero() || compareSortKeys(seek, lower) > 0 {
+			lower = seek
+		}
+	}
+
+	return lower, upper
+}
+
+// filter will build the filter of the query. Empty is set when the seek position is past the range, so nothing matches.
+func (q *SortedMapQuery[T]) filter(descending bool) (itemFilter *string, empty bool, err error) {
+	lower, upper := q.bounds(descending)
+	if lower.IsZero() && upper.IsZero() {
+		return nil, false, nil
+	}
+	if q.afterId != nil && !lower.IsZero() && !upper.IsZero() && compareSortKeys(lower, upper) >= 0 {
+		return nil, true, nil
+	}
+
+	b := filter.SortedMap()
+	if !lower.IsZero() {
+		b.SortKeyGreaterThan(lower.value())
+	}
+	if !upper.IsZero() {
+		b.SortKeyLessThan(upper.value())
+	}
+
+	f, err := b.Build()
+	if err != nil {
+		return nil, false, err
+	}
+	return &f, false, nil
+}
+
+// afterSeek will report if an item comes after the seek position in the direction of the iteration.
+func (q *SortedMapQuery[T]) afterSeek(item MemoryStoreSortedMapItem, descending bool) bool {
+	c := compareSortKeys(item.SortKey(), q.afterKey)
+	if c == 0 {
+		c = strings.Compare(item.ID, *q.afterId)
+	}
+	if descending {
+		c = -c
+	}
+
+	return c > 0
+}
+
+// iterate will page through the matching items. Iteration stops after the first error, which is yielded with a zero item.
+func (q *SortedMapQuery[T]) iterate(ctx context.Context, descending bool) iter.Seq2[SortedMapItem[T], error] {
+	return func(yield func(SortedMapItem[T], error) bool) {
+		itemFilter, empty, err := q.filter(descending)
+		if err != nil {
+			yield(SortedMapItem[T]{}, err)
+			return
+		}
+		if empty {
+			return
+		}
+		// Items are listed in order, so once an item is past the seek position every later item is too.
+		seeking := q.afterId != nil
+
+		opts := &MemoryStoreSortedMapItemListOptions{
+			MaxPageSize: Pointer(q.m.PageSize),
+			Filter:      itemFilter,
+		}
+		if descending {
+			opts.OrderBy = Pointer("desc")
+		}
+
+		for {
+			list, resp, err := q.m.service.ListMemoryStoreSortedMapItems(ctx, q.m.universeId, q.m.sortedMapId, opts)
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				yield(SortedMapItem[T]{}, err)
+				return
+			}
+
+			for _, item := range list.MemoryStoreSortedMapItems {
+				if seeking && !q.afterSeek(item, descending) {
+					continue
+				}
+				seeking = false
+
+				decoded, err := decodeSortedMapItem[T](item)
+				if !yield(decoded, err) || err != nil {
+					return
+				}
+			}
+
+			if list.NextPageToken == "" {
+				return
+			}
+			opts.PageToken = Pointer(list.NextPageToken)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,11 +26,15 @@ type Client struct {
 	client *http.Client
 	common service
 
+	dataStoreCache   *DataStoreCache
+	validateRequests bool
+
 	BaseURL *url.URL
 
 	// v1 Opencloud API services
 
-	Assets *AssetsService
+	Assets      *AssetsService
+	DataStoreV1 *DataStoreV1Service
 
 	// v2 Opencloud API services
 
@@ -87,6 +92,7 @@ func (c *Client) init() *Client {
 
 	// v1
 	c.Assets = (*AssetsService)(&c.common)
+	c.DataStoreV1 = (*DataStoreV1Service)(&c.common)
 
 	// v2
 	c.Config = (*ConfigService)(&c.common)
@@ -137,6 +143,12 @@ func (c *Client) NewRequest(method, urlString string, body any) (*http.Request,
 	}
 
 	if body != nil {
+		if validator, ok := body.(Validator); ok && c.validateRequests {
+			if err := validator.Validate(); err != nil {
+				return nil, err
+			}
+		}
+
 		var buf bytes.Buffer
 		encoder := json.NewEncoder(&buf)
 		encoder.SetEscapeHTML(false)
@@ -191,6 +203,30 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v any) (*Response, e
 	return response, err
 }
 
+// ResponseError is returned by the higher level helpers when the API responds with a non-successful status code.
+type ResponseError struct {
+	Response *Response
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Response.Request.Method, e.Response.Request.URL.Path, e.Response.Status)
+}
+
+// checkResponse will return a ResponseError if the response does not have a 2xx status code.
+func checkResponse(resp *Response) error {
+	if resp == nil || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+
+	return &ResponseError{Response: resp}
+}
+
+// isStatus will check if the error is a ResponseError with the specified status code.
+func isStatus(err error, statusCode int) bool {
+	var respErr *ResponseError
+	return errors.As(err, &respErr) && respErr.Response.StatusCode == statusCode
+}
+
 func addOpts(urlString string, opts any) (string, error) {
 	v := reflect.ValueOf(opts)
 	if v.Kind() == reflect.Ptr && v.IsNil() {
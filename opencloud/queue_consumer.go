@@ -0,0 +1,190 @@
+package opencloud
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// QueueHandlerFunc will process a batch of queue items. The batch is discarded when it returns nil,
+// and becomes visible again once the invisibility window expires when it returns an error.
+type QueueHandlerFunc func(ctx context.Context, items []MemoryStoreQueueItem) error
+
+type QueueConsumerMetrics struct {
+	StartTime time.Time
+	// Batches and Items are the amount of batches and items handed to the handler.
+	Batches int64
+	Items   int64
+	// Acknowledged are items that were handled and discarded.
+	Acknowledged int64
+	// Failed are items the handler returned an error for, which become visible again.
+	Failed int64
+	// Errors are failed reads and discards.
+	Errors int64
+	// ItemsPerSecond is the rate of acknowledged items since the consumer started.
+	ItemsPerSecond float64
+}
+
+// QueueConsumer will read batches from a memory store queue with several workers, and discard them once they were handled.
+type QueueConsumer struct {
+	service    *DataAndMemoryStoreService
+	universeId string
+	queueId    string
+	handler    QueueHandlerFunc
+
+	// InvisibilityWindow is how long read items are hidden from other readers. A batch has to be handled within the window,
+	// otherwise it becomes visible again and discarding it fails. Defaults to 30 seconds.
+	InvisibilityWindow time.Duration
+	// Count is the maximum amount of items in a batch. Defaults to 10.
+	Count int
+	// AllOrNothing will only return a batch when Count items are available.
+	AllOrNothing bool
+	// Workers is the amount of batches handled at the same time. Defaults to 1.
+	Workers int
+	// PollInterval is how long a worker waits after an empty read. It doubles for every empty read up to MaxPollInterval.
+	// Defaults to 1 second and 30 seconds.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+	// ShutdownTimeout is how long batches that are being handled can finish after the context is cancelled.
+	// Defaults to the invisibility window.
+	ShutdownTimeout time.Duration
+	// OnError is called when a read, handler or discard fails.
+	OnError func(err error)
+
+	startTime                                        atomic.Int64
+	batches, items, acknowledged, failed, errorCount atomic.Int64
+}
+
+// NewQueueConsumer will create a consumer for a memory store queue under a specific universe.
+func (s *DataAndMemoryStoreService) NewQueueConsumer(universeId, queueId string, handler QueueHandlerFunc) *QueueConsumer {
+	return &QueueConsumer{
+		service:            s,
+		universeId:         universeId,
+		queueId:            queueId,
+		handler:            handler,
+		InvisibilityWindow: 30 * time.Second,
+		Count:              10,
+		Workers:            1,
+		PollInterval:       time.Second,
+		MaxPollInterval:    30 * time.Second,
+	}
+}
+
+// Metrics will return the throughput of the consumer.
+func (c *QueueConsumer) Metrics() QueueConsumerMetrics {
+	metrics := QueueConsumerMetrics{
+		Batches:      c.batches.Load(),
+		Items:        c.items.Load(),
+		Acknowledged: c.acknowledged.Load(),
+		Failed:       c.failed.Load(),
+		Errors:       c.errorCount.Load(),
+	}
+	if started := c.startTime.Load(); started != 0 {
+		metrics.StartTime = time.Unix(0, started)
+		if elapsed := time.Since(metrics.StartTime).Seconds(); elapsed > 0 {
+			metrics.ItemsPerSecond = float64(metrics.Acknowledged) / elapsed
+		}
+	}
+
+	return metrics
+}
+
+// Run will consume the queue until the context is cancelled. Batches that are being handled when the context is cancelled
+// can finish and are discarded, no new batches are read.
+func (c *QueueConsumer) Run(ctx context.Context) error {
+	c.startTime.Store(time.Now().UnixNano())
+
+	shutdownTimeout := c.ShutdownTimeout
+	if shutdownTimeout == 0 {
+		shutdownTimeout = c.InvisibilityWindow
+	}
+
+	// Handlers use a context that outlives the cancellation of ctx by the shutdown timeout.
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(shutdownTimeout, cancelHandlers)
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	for range max(c.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.work(ctx, handlerCtx)
+		}()
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+func (c *QueueConsumer) work(ctx, handlerCtx context.Context) {
+	wait := time.Duration(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		items, resp, err := c.service.ReadMemoryStoreQueueItems(ctx, c.universeId, c.queueId, &MemoryStoreQueueItemsOptions{
+			Count:              Pointer(c.Count),
+			AllOrNothing:       Pointer(c.AllOrNothing),
+			InvisibilityWindow: Pointer(fmt.Sprintf("%ds", int(c.InvisibilityWindow.Seconds()))),
+		})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.errorCount.Add(1)
+			c.error(err)
+			wait = min(max(wait*2, c.PollInterval, retryAfter(resp)), c.MaxPollInterval)
+			continue
+		}
+
+		if len(items.Items) == 0 {
+			wait = min(max(wait*2, c.PollInterval), c.MaxPollInterval)
+			continue
+		}
+		wait = 0
+
+		c.handle(handlerCtx, items)
+	}
+}
+
+func (c *QueueConsumer) handle(ctx context.Context, items *MemoryStoreQueueItems) {
+	count := int64(len(items.Items))
+	c.batches.Add(1)
+	c.items.Add(count)
+
+	if err := c.handler(ctx, items.Items); err != nil {
+		c.failed.Add(count)
+		c.error(fmt.Errorf("handling %d items of read %s: %w", count, items.ReadID, err))
+		return
+	}
+
+	resp, err := c.service.DiscardMemoryStoreQueueItems(ctx, c.universeId, c.queueId, MemoryStoreQueueItemsDiscard{ReadID: items.ReadID})
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		c.errorCount.Add(1)
+		c.error(fmt.Errorf("discarding read %s: %w", items.ReadID, err))
+		return
+	}
+
+	c.acknowledged.Add(count)
+}
+
+func (c *QueueConsumer) error(err error) {
+	if c.OnError != nil {
+		c.OnError(err)
+	}
+}
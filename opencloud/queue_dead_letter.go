@@ -0,0 +1,248 @@
+package opencloud
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// queueEnvelopeVersion marks queue item data as a QueueEnvelope, so it can be told apart from data enqueued by game servers.
+const queueEnvelopeVersion = 1
+
+// QueueEnvelope wraps the data of a queue item to track how often it failed.
+// Items that were not enqueued with an envelope are treated as being delivered for the first time.
+type QueueEnvelope struct {
+	Envelope       int    `json:"envelope"`
+	Data           any    `json:"data"`
+	Attempts       int    `json:"attempts"`
+	FirstSeenTime  string `json:"firstSeenTime"`
+	LastError      string `json:"lastError,omitempty"`
+	SourceQueue    string `json:"sourceQueue,omitempty"`
+	DeadLetterTime string `json:"deadLetterTime,omitempty"`
+}
+
+// NewQueueEnvelope will wrap data in an envelope that has not been attempted yet.
+func NewQueueEnvelope(data any) QueueEnvelope {
+	return QueueEnvelope{
+		Envelope:      queueEnvelopeVersion,
+		Data:          data,
+		FirstSeenTime: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// UnwrapQueueEnvelope will return the envelope of an item, or a new envelope around its data if it does not have one.
+func UnwrapQueueEnvelope(item MemoryStoreQueueItem) QueueEnvelope {
+	if data, ok := item.Data.(map[string]any); ok && data["envelope"] == float64(queueEnvelopeVersion) {
+		var envelope QueueEnvelope
+		if err := decodeValue(data, &envelope); err == nil {
+			return envelope
+		}
+	}
+
+	return NewQueueEnvelope(item.Data)
+}
+
+// QueueItemHandlerFunc will process a single queue item. The envelope holds the unwrapped data and the previous attempts.
+type QueueItemHandlerFunc func(ctx context.Context, item MemoryStoreQueueItem, envelope QueueEnvelope) error
+
+// DeadLetterQueue will retry failed queue items and move them to a companion queue once they failed too often.
+type DeadLetterQueue struct {
+	service           *DataAndMemoryStoreService
+	universeId        string
+	queueId           string
+	deadLetterQueueId string
+
+	// MaxAttempts is the amount of times an item is handled before it is moved to the dead-letter queue. Defaults to 5.
+	MaxAttempts int
+	// DeadLetterTTL is how long items are kept in the dead-letter queue. Defaults to the maximum of 45 days.
+	DeadLetterTTL time.Duration
+
+	// moved are the paths of items that were already enqueued elsewhere, with their expire time. Items can only be
+	// discarded by batch, so they are delivered again when another item of their batch could not be moved.
+	mu    sync.Mutex
+	moved map[string]time.Time
+}
+
+// NewDeadLetterQueue will create retry handling for a queue, which moves items that keep failing to the dead-letter queue.
+func (s *DataAndMemoryStoreService) NewDeadLetterQueue(universeId, queueId, deadLetterQueueId string) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		service:           s,
+		universeId:        universeId,
+		queueId:           queueId,
+		deadLetterQueueId: deadLetterQueueId,
+		MaxAttempts:       5,
+		DeadLetterTTL:     MaxMemoryStoreItemTTL,
+		moved:             make(map[string]time.Time),
+	}
+}
+
+// markMoved will remember that an item was enqueued elsewhere until it expires, so it is not moved again when it is delivered again.
+func (d *DeadLetterQueue) markMoved(item MemoryStoreQueueItem) {
+	expire, err := time.Parse(time.RFC3339Nano, item.ExpireTime)
+	if err != nil {
+		expire = time.Now().Add(MaxMemoryStoreItemTTL)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for path, expire := range d.moved {
+		if now.After(expire) {
+			delete(d.moved, path)
+		}
+	}
+	d.moved[item.Path] = expire
+}
+
+func (d *DeadLetterQueue) isMoved(item MemoryStoreQueueItem) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, ok := d.moved[item.Path]
+	return ok
+}
+
+func (d *DeadLetterQueue) enqueue(ctx context.Context, queueId string, envelope QueueEnvelope, priority int, ttl time.Duration) error {
+	var data any = envelope
+	_, resp, err := d.service.CreateMemoryStoreQueueItem(ctx, d.universeId, queueId, MemoryStoreQueueItemCreate{
+		Data:     &data,
+		Priority: Pointer(priority),
+		TTL:      memoryStoreTTL(ttl),
+	})
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	return err
+}
+
+// Handler will wrap an item handler into a batch handler for QueueConsumer.
+//
+// Items that fail are enqueued again with their attempt count increased, or moved to the dead-letter queue once they reach MaxAttempts.
+// The batch is only kept when an item could not be enqueued again, in which case it is delivered again. Items of the batch that
+// were already enqueued again or moved are skipped when it is delivered again, as long as it is handled by the same DeadLetterQueue.
+func (d *DeadLetterQueue) Handler(handler QueueItemHandlerFunc) QueueHandlerFunc {
+	return func(ctx context.Context, items []MemoryStoreQueueItem) error {
+		for _, item := range items {
+			if d.isMoved(item) {
+				continue
+			}
+			envelope := UnwrapQueueEnvelope(item)
+
+			err := handler(ctx, item, envelope)
+			if err == nil {
+				continue
+			}
+
+			envelope.Envelope = queueEnvelopeVersion
+			envelope.Attempts++
+			envelope.LastError = err.Error()
+
+			if envelope.Attempts >= d.MaxAttempts {
+				envelope.SourceQueue = d.queueId
+				envelope.DeadLetterTime = time.Now().UTC().Format(time.RFC3339Nano)
+				if err := d.enqueue(ctx, d.deadLetterQueueId, envelope, item.Priority, d.DeadLetterTTL); err != nil {
+					return fmt.Errorf("moving item %s to dead-letter queue: %w", item.ID, err)
+				}
+				d.markMoved(item)
+				continue
+			}
+
+			// The retry keeps the remaining time to live of the original item.
+			ttl := MaxMemoryStoreItemTTL
+			if expire, err := time.Parse(time.RFC3339Nano, item.ExpireTime); err == nil {
+				ttl = time.Until(expire)
+			}
+			if err := d.enqueue(ctx, d.queueId, envelope, item.Priority, ttl); err != nil {
+				return fmt.Errorf("retrying item %s: %w", item.ID, err)
+			}
+			d.markMoved(item)
+		}
+
+		return nil
+	}
+}
+
+type DeadLetter struct {
+	Item     MemoryStoreQueueItem
+	Envelope QueueEnvelope
+}
+
+// Inspect will read up to count items from the dead-letter queue without removing them.
+// The items are hidden from other readers until the invisibility window expires.
+func (d *DeadLetterQueue) Inspect(ctx context.Context, count int, invisibilityWindow time.Duration) ([]DeadLetter, error) {
+	items, resp, err := d.service.ReadMemoryStoreQueueItems(ctx, d.universeId, d.deadLetterQueueId, &MemoryStoreQueueItemsOptions{
+		Count:              Pointer(count),
+		InvisibilityWindow: memoryStoreTTL(invisibilityWindow),
+	})
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	letters := make([]DeadLetter, len(items.Items))
+	for i, item := range items.Items {
+		letters[i] = DeadLetter{Item: item, Envelope: UnwrapQueueEnvelope(item)}
+	}
+
+	return letters, nil
+}
+
+// Redrive will move up to limit items from the dead-letter queue back to the queue, with their attempt count reset.
+// Every item is moved when limit is 0. The amount of items that were moved is returned.
+//
+// When an item can not be moved, the items of its batch that were already moved are skipped by the next Redrive.
+func (d *DeadLetterQueue) Redrive(ctx context.Context, limit int) (int, error) {
+	moved := 0
+	for limit == 0 || moved < limit {
+		count := 100
+		if limit != 0 {
+			count = min(count, limit-moved)
+		}
+
+		items, resp, err := d.service.ReadMemoryStoreQueueItems(ctx, d.universeId, d.deadLetterQueueId, &MemoryStoreQueueItemsOptions{
+			Count:              Pointer(count),
+			InvisibilityWindow: memoryStoreTTL(30 * time.Second),
+		})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return moved, err
+		}
+		if len(items.Items) == 0 {
+			return moved, nil
+		}
+
+		for _, item := range items.Items {
+			if d.isMoved(item) {
+				continue
+			}
+
+			envelope := UnwrapQueueEnvelope(item)
+			envelope.Attempts = 0
+			envelope.SourceQueue = ""
+			envelope.DeadLetterTime = ""
+
+			if err := d.enqueue(ctx, d.queueId, envelope, item.Priority, MaxMemoryStoreItemTTL); err != nil {
+				return moved, fmt.Errorf("redriving item %s: %w", item.ID, err)
+			}
+			d.markMoved(item)
+			moved++
+		}
+
+		resp, err = d.service.DiscardMemoryStoreQueueItems(ctx, d.universeId, d.deadLetterQueueId, MemoryStoreQueueItemsDiscard{ReadID: items.ReadID})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return moved, err
+		}
+	}
+
+	return moved, nil
+}
@@ -0,0 +1,297 @@
+package opencloud
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueProducerClosed is returned by Send once the producer was closed.
+	ErrQueueProducerClosed = errors.New("queue producer is closed")
+	// ErrQueueProducerNotStarted is returned by Send before the producer was started.
+	ErrQueueProducerNotStarted = errors.New("queue producer is not started")
+	// ErrQueueProducerStarted is returned by Start when the producer was already started.
+	ErrQueueProducerStarted = errors.New("queue producer is already started")
+)
+
+// QueueDelivery is the result of sending a payload to a memory store queue.
+type QueueDelivery[T any] struct {
+	Payload T
+	// Item is the created queue item, or nil if the payload could not be delivered.
+	Item     *MemoryStoreQueueItem
+	Attempts int
+	Error    error
+}
+
+// QueueProducer will send typed payloads to a memory store queue. Payloads are JSON encoded as the item data,
+// and are sent in batches by several workers.
+//
+// Configure the producer before calling Start, send payloads with Send, and call Close to deliver the remaining payloads.
+type QueueProducer[T any] struct {
+	service    *DataAndMemoryStoreService
+	universeId string
+	queueId    string
+
+	// Workers is the amount of batches sent at the same time. Defaults to 4.
+	Workers int
+	// BatchSize is the maximum amount of payloads sent at the same time by a worker. Defaults to 10.
+	BatchSize int
+	// BatchDelay is how long a worker waits for a batch to fill up before sending it. Defaults to 10 milliseconds.
+	BatchDelay time.Duration
+	// Buffer is the amount of payloads that can be sent before Send blocks. Defaults to 100.
+	Buffer int
+	// Priority will return the priority of a payload. Items have the default priority when nil.
+	Priority func(payload T) int
+	// TTL will return how long the item of a payload is kept in the queue. Items have the default TTL when nil.
+	TTL func(payload T) time.Duration
+	// MaxAttempts is the amount of times a throttled payload is sent before it is reported as failed. Defaults to 5.
+	MaxAttempts int
+	// OnDelivery is called with the result of every payload.
+	OnDelivery func(delivery QueueDelivery[T])
+
+	input      chan T
+	done       chan struct{}
+	deliveries chan QueueDelivery[T]
+	wg         sync.WaitGroup
+	sending    sync.WaitGroup
+	mu         sync.RWMutex
+	closed     bool
+	finished   bool
+}
+
+// NewQueueProducer will create a producer for a memory store queue under a specific universe.
+func NewQueueProducer[T any](s *DataAndMemoryStoreService, universeId, queueId string) *QueueProducer[T] {
+	return &QueueProducer[T]{
+		service:     s,
+		universeId:  universeId,
+		queueId:     queueId,
+		Workers:     4,
+		BatchSize:   10,
+		BatchDelay:  10 * time.Millisecond,
+		Buffer:      100,
+		MaxAttempts: 5,
+	}
+}
+
+// Deliveries will report the result of every payload on a channel, which is closed once the producer is closed.
+// It should be called before Start so no results are missed, and the channel has to be drained, otherwise the workers block.
+func (p *QueueProducer[T]) Deliveries() <-chan QueueDelivery[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.deliveries == nil {
+		p.deliveries = make(chan QueueDelivery[T], p.Buffer)
+		if p.finished {
+			close(p.deliveries)
+		}
+	}
+
+	return p.deliveries
+}
+
+// Start will start the workers. ErrQueueProducerStarted is returned if it was already started,
+// and ErrQueueProducerClosed if it was closed.
+func (p *QueueProducer[T]) Start(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrQueueProducerClosed
+	}
+	if p.input != nil {
+		p.mu.Unlock()
+		return ErrQueueProducerStarted
+	}
+	p.input = make(chan T, p.Buffer)
+	p.done = make(chan struct{})
+	p.mu.Unlock()
+
+	for range max(p.Workers, 1) {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	return nil
+}
+
+// Send will queue a payload to be sent, blocking while the buffer is full.
+// ErrQueueProducerClosed is returned if the producer is closed while Send is blocked.
+func (p *QueueProducer[T]) Send(ctx context.Context, payload T) error {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrQueueProducerClosed
+	}
+	if p.input == nil {
+		p.mu.RUnlock()
+		return ErrQueueProducerNotStarted
+	}
+	// Close waits for sends in progress before closing the input, so the lock does not have to be held while blocked.
+	p.sending.Add(1)
+	p.mu.RUnlock()
+	defer p.sending.Done()
+
+	select {
+	case p.input <- payload:
+		return nil
+	case <-p.done:
+		return ErrQueueProducerClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close will stop accepting payloads, and wait until every queued payload was delivered.
+// It can be called before Start, in which case nothing is delivered.
+func (p *QueueProducer[T]) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.wg.Wait()
+		return
+	}
+	p.closed = true
+	if p.done != nil {
+		close(p.done)
+	}
+	p.mu.Unlock()
+
+	p.sending.Wait()
+	if p.input != nil {
+		close(p.input)
+	}
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	p.finished = true
+	if p.deliveries != nil {
+		close(p.deliveries)
+	}
+	p.mu.Unlock()
+}
+
+func (p *QueueProducer[T]) work(ctx context.Context) {
+	for {
+		payload, ok := <-p.input
+		if !ok {
+			return
+		}
+
+		batch := []T{payload}
+		timer := time.NewTimer(p.BatchDelay)
+	fill:
+		for len(batch) < p.BatchSize {
+			select {
+			case payload, ok := <-p.input:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, payload)
+			case <-timer.C:
+				break fill
+			}
+		}
+		timer.Stop()
+
+		p.sendBatch(ctx, batch)
+	}
+}
+
+// sendBatch will send every payload of the batch at the same time. Throttled payloads are sent again after backing off.
+func (p *QueueProducer[T]) sendBatch(ctx context.Context, batch []T) {
+	deliveries := make([]QueueDelivery[T], len(batch))
+	for i, payload := range batch {
+		deliveries[i].Payload = payload
+	}
+
+	pending := make([]int, len(batch))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	backoff := 500 * time.Millisecond
+	for len(pending) > 0 {
+		var mu sync.Mutex
+		var throttled []int
+		var wait time.Duration
+
+		var wg sync.WaitGroup
+		for _, i := range pending {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				delivery := &deliveries[i]
+				delivery.Attempts++
+				delivery.Item, delivery.Error = p.send(ctx, delivery.Payload)
+
+				if isStatus(delivery.Error, http.StatusTooManyRequests) && delivery.Attempts < p.MaxAttempts {
+					var respErr *ResponseError
+					errors.As(delivery.Error, &respErr)
+
+					mu.Lock()
+					throttled = append(throttled, i)
+					wait = max(wait, retryAfter(respErr.Response))
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		pending = throttled
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, i := range pending {
+				deliveries[i].Error = ctx.Err()
+			}
+			pending = nil
+		case <-time.After(max(wait, backoff)):
+			backoff *= 2
+		}
+	}
+
+	// The channel is only closed once every worker stopped, so it can be sent on without holding the lock.
+	p.mu.RLock()
+	results := p.deliveries
+	p.mu.RUnlock()
+
+	for _, delivery := range deliveries {
+		if p.OnDelivery != nil {
+			p.OnDelivery(delivery)
+		}
+		if results != nil {
+			results <- delivery
+		}
+	}
+}
+
+func (p *QueueProducer[T]) send(ctx context.Context, payload T) (*MemoryStoreQueueItem, error) {
+	var data any = payload
+	create := MemoryStoreQueueItemCreate{Data: &data}
+	if p.Priority != nil {
+		create.Priority = Pointer(p.Priority(payload))
+	}
+	if p.TTL != nil {
+		create.TTL = memoryStoreTTL(p.TTL(payload))
+	}
+
+	item, resp, err := p.service.CreateMemoryStoreQueueItem(ctx, p.universeId, p.queueId, create)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
@@ -0,0 +1,407 @@
+package opencloud
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type ShardSelection string
+
+const (
+	// ShardSelectionRandom spreads increments evenly over every shard.
+	ShardSelectionRandom ShardSelection = "RANDOM"
+	// ShardSelectionHash sends increments with the same hash key to the same shard.
+	ShardSelectionHash ShardSelection = "HASH"
+)
+
+// shardedCounterConfig is stored in a data store entry so every process uses the same amount of shards.
+type shardedCounterConfig struct {
+	// Shards is the amount of shards that are written to.
+	Shards int `json:"shards"`
+	// ReadShards is the amount of shards that are read, which can be higher than Shards until a shrink has finished.
+	ReadShards  int    `json:"readShards"`
+	ReshardTime string `json:"reshardTime"`
+	// PendingMove is the value being moved out of a removed shard, so a reshard that was interrupted can finish it.
+	PendingMove *shardedCounterMove `json:"pendingMove,omitempty"`
+}
+
+// shardedCounterMoveAttribute is set on the increments of a move, so a resumed reshard can tell which were applied.
+const shardedCounterMoveAttribute = "shardedCounterMove"
+
+type shardedCounterMove struct {
+	ID        string `json:"id"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Amount    int    `json:"amount"`
+	StartTime string `json:"startTime"`
+}
+
+// ShardedCounter is a counter that spreads increments over multiple data store entries, so a single key does not take every write.
+type ShardedCounter struct {
+	service     *DataAndMemoryStoreService
+	universeId  string
+	dataStoreId string
+	scope       *string
+	name        string
+	shards      int
+
+	// Selection defaults to ShardSelectionRandom.
+	Selection ShardSelection
+	// ConfigRefresh is how long the shard configuration is cached before it is fetched again.
+	ConfigRefresh time.Duration
+
+	mu          sync.Mutex
+	config      *shardedCounterConfig
+	configEtag  string
+	configFetch time.Time
+}
+
+// NewShardedCounter will create a counter with a specific amount of shards in a data store under a specific universe.
+// The amount of shards is only used when the counter does not exist yet, otherwise the stored configuration is used.
+func (s *DataAndMemoryStoreService) NewShardedCounter(universeId, dataStoreId string, scope *string, name string, shards int) *ShardedCounter {
+	return &ShardedCounter{
+		service:       s,
+		universeId:    universeId,
+		dataStoreId:   dataStoreId,
+		scope:         scope,
+		name:          name,
+		shards:        max(shards, 1),
+		Selection:     ShardSelectionRandom,
+		ConfigRefresh: 30 * time.Second,
+	}
+}
+
+func (c *ShardedCounter) configKey() string {
+	return fmt.Sprintf("%s:config", c.name)
+}
+
+// ShardKey will return the entry key of a shard.
+func (c *ShardedCounter) ShardKey(shard int) string {
+	return fmt.Sprintf("%s:shard:%d", c.name, shard)
+}
+
+// loadConfig will return the cached shard configuration, fetching or creating it when the cache is stale.
+func (c *ShardedCounter) loadConfig(ctx context.Context, force bool) (*shardedCounterConfig, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !force && c.config != nil && time.Since(c.configFetch) < c.ConfigRefresh {
+		return c.config, c.configEtag, nil
+	}
+
+	entry, resp, err := c.service.GetDataStoreEntry(withoutDataStoreCache(ctx), c.universeId, c.dataStoreId, c.scope, c.configKey())
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	config := new(shardedCounterConfig)
+	switch {
+	case isStatus(err, http.StatusNotFound):
+		config.Shards, config.ReadShards = c.shards, c.shards
+
+		var value any = config
+		entry, resp, err = c.service.CreateDataStoreEntry(ctx, c.universeId, c.dataStoreId, c.scope, DataStoreEntryCreate{
+			Value: &value,
+		}, &DataStoreEntryCreateOptions{ID: Pointer(c.configKey())})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	case err != nil:
+		return nil, "", err
+	default:
+		if err := decodeValue(entry.Value, config); err != nil {
+			return nil, "", err
+		}
+	}
+
+	c.config, c.configEtag, c.configFetch = config, entry.Etag, time.Now()
+	return config, entry.Etag, nil
+}
+
+func (c *ShardedCounter) saveConfig(ctx context.Context, config *shardedCounterConfig, etag string) (string, error) {
+	var value any = config
+	entry, resp, err := c.service.UpdateDataStoreEntry(ctx, c.universeId, c.dataStoreId, c.scope, c.configKey(), DataStoreEntryUpdate{
+		Etag:  Pointer(etag),
+		Value: &value,
+	}, nil)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.config, c.configEtag, c.configFetch = config, entry.Etag, time.Now()
+	c.mu.Unlock()
+	return entry.Etag, nil
+}
+
+func (c *ShardedCounter) increment(ctx context.Context, shard, amount int) error {
+	_, resp, err := c.service.IncrementDataStoreEntry(ctx, c.universeId, c.dataStoreId, c.scope, c.ShardKey(shard), DataStoreEntryIncrement{
+		Amount: Pointer(amount),
+	})
+	if err == nil {
+		err = checkResponse(resp)
+	}
+
+	return err
+}
+
+// Increment will add the amount to one of the shards.
+// The hash key picks the shard when Selection is ShardSelectionHash, and it is ignored otherwise.
+func (c *ShardedCounter) Increment(ctx context.Context, amount int, hashKey string) error {
+	config, _, err := c.loadConfig(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	shard := rand.IntN(config.Shards)
+	if c.Selection == ShardSelectionHash {
+		h := fnv.New32a()
+		h.Write([]byte(hashKey))
+		shard = int(h.Sum32() % uint32(config.Shards))
+	}
+
+	return c.increment(ctx, shard, amount)
+}
+
+// shardValue will fetch the value of a shard. Shards that were never written to are 0.
+func (c *ShardedCounter) shardValue(ctx context.Context, shard int) (int, error) {
+	entry, resp, err := c.service.GetDataStoreEntry(withoutDataStoreCache(ctx), c.universeId, c.dataStoreId, c.scope, c.ShardKey(shard))
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if isStatus(err, http.StatusNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var value int
+	if err := decodeValue(entry.Value, &value); err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// Total will fetch every shard concurrently and return the sum.
+func (c *ShardedCounter) Total(ctx context.Context) (int, error) {
+	config, _, err := c.loadConfig(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+
+	values := make([]int, config.ReadShards)
+	errs := make([]error, config.ReadShards)
+
+	var wg sync.WaitGroup
+	for shard := range config.ReadShards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[shard], errs[shard] = c.shardValue(ctx, shard)
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for shard := range config.ReadShards {
+		if errs[shard] != nil {
+			return 0, errs[shard]
+		}
+		total += values[shard]
+	}
+
+	return total, nil
+}
+
+// Reshard will change the amount of shards while the counter is in use.
+//
+// Growing takes effect immediately. When shrinking, the value of every removed shard is moved into the remaining shards,
+// and the removed shards keep being read until processes with a cached configuration have stopped writing to them.
+// Call Reshard again with the same amount after ConfigRefresh has passed to move any late writes and stop reading the removed shards.
+func (c *ShardedCounter) Reshard(ctx context.Context, shards int) error {
+	if shards < 1 {
+		return fmt.Errorf("sharded counter %q: shards must be at least 1", c.name)
+	}
+
+	config, etag, err := c.loadConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	if config.PendingMove != nil {
+		if config, etag, err = c.finishMove(ctx, config, etag, true); err != nil {
+			return err
+		}
+	}
+
+	if shards >= config.ReadShards {
+		_, err := c.saveConfig(ctx, &shardedCounterConfig{
+			Shards:      shards,
+			ReadShards:  shards,
+			ReshardTime: time.Now().UTC().Format(time.RFC3339Nano),
+		}, etag)
+		return err
+	}
+
+	settled := false
+	if config.Shards == shards {
+		reshardTime, err := time.Parse(time.RFC3339Nano, config.ReshardTime)
+		settled = err == nil && time.Since(reshardTime) > c.ConfigRefresh
+	} else {
+		config = &shardedCounterConfig{
+			Shards:      shards,
+			ReadShards:  config.ReadShards,
+			ReshardTime: time.Now().UTC().Format(time.RFC3339Nano),
+		}
+		if etag, err = c.saveConfig(ctx, config, etag); err != nil {
+			return err
+		}
+	}
+
+	// Removed shards are drained with increments, so concurrent writes to either shard are never lost.
+	// The move is recorded in the configuration first, so an interrupted move is finished by the next call to Reshard.
+	// The target is written first, so the total can briefly be higher while a value is moved, but it never loses a value.
+	for shard := shards; shard < config.ReadShards; shard++ {
+		value, err := c.shardValue(ctx, shard)
+		if err != nil {
+			return err
+		}
+		if value == 0 {
+			continue
+		}
+
+		next := *config
+		next.PendingMove = &shardedCounterMove{
+			ID:        strconv.FormatUint(rand.Uint64(), 36),
+			From:      shard,
+			To:        shard % shards,
+			Amount:    value,
+			StartTime: time.Now().UTC().Format(time.RFC3339Nano),
+		}
+		if etag, err = c.saveConfig(ctx, &next, etag); err != nil {
+			return err
+		}
+		if config, etag, err = c.finishMove(ctx, &next, etag, false); err != nil {
+			return err
+		}
+	}
+
+	if !settled {
+		return nil
+	}
+
+	_, err = c.saveConfig(ctx, &shardedCounterConfig{
+		Shards:      shards,
+		ReadShards:  shards,
+		ReshardTime: config.ReshardTime,
+	}, etag)
+	return err
+}
+
+// finishMove will apply the increments of the pending move and clear it from the configuration.
+// When a move is resumed, increments that were already applied are skipped.
+func (c *ShardedCounter) finishMove(ctx context.Context, config *shardedCounterConfig, etag string, resumed bool) (*shardedCounterConfig, string, error) {
+	move := config.PendingMove
+
+	steps := []struct{ shard, amount int }{{move.To, move.Amount}, {move.From, -move.Amount}}
+	for _, step := range steps {
+		if resumed {
+			applied, err := c.moveApplied(ctx, step.shard, move)
+			if err != nil {
+				return nil, "", err
+			}
+			if applied {
+				continue
+			}
+		}
+
+		_, resp, err := c.service.IncrementDataStoreEntry(ctx, c.universeId, c.dataStoreId, c.scope, c.ShardKey(step.shard), DataStoreEntryIncrement{
+			Amount:     Pointer(step.amount),
+			Attributes: &map[string]any{shardedCounterMoveAttribute: move.ID},
+		})
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	next := *config
+	next.PendingMove = nil
+	etag, err := c.saveConfig(ctx, &next, etag)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &next, etag, nil
+}
+
+// moveApplied will look through the revisions of a shard written since the move started for an increment of the move.
+func (c *ShardedCounter) moveApplied(ctx context.Context, shard int, move *shardedCounterMove) (bool, error) {
+	start, err := time.Parse(time.RFC3339Nano, move.StartTime)
+	if err != nil {
+		return false, err
+	}
+	// Revision times are set by Roblox, so some clock skew is allowed for.
+	start = start.Add(-time.Minute)
+
+	key := c.ShardKey(shard)
+	opts := &Options{MaxPageSize: Pointer(100)}
+	for {
+		// Revisions are listed newest first, so paging stops at the first revision from before the move.
+		revisions, resp, err := c.service.ListDataStoreEntryRevisions(ctx, c.universeId, c.dataStoreId, c.scope, key, opts)
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if isStatus(err, http.StatusNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		for _, revision := range revisions.DataStoreEntries {
+			created, err := time.Parse(time.RFC3339Nano, revision.RevisionCreationTime)
+			if err != nil {
+				return false, err
+			}
+			if created.Before(start) {
+				return false, nil
+			}
+			if revision.State == DataStoreEntryStateDeleted {
+				continue
+			}
+
+			full, resp, err := c.service.GetDataStoreEntry(ctx, c.universeId, c.dataStoreId, c.scope, fmt.Sprintf("%s@%s", key, revision.RevisionID))
+			if err == nil {
+				err = checkResponse(resp)
+			}
+			if err != nil {
+				return false, err
+			}
+			if full.Attributes[shardedCounterMoveAttribute] == move.ID {
+				return true, nil
+			}
+		}
+
+		if revisions.NextPageToken == "" {
+			return false, nil
+		}
+		opts.PageToken = Pointer(revisions.NextPageToken)
+	}
+}
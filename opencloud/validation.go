@@ -0,0 +1,280 @@
+package opencloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Documented limits of the Opencloud API that are checked by Validate.
+const (
+	MaxDataStoreNameLength          = 50
+	MaxDataStoreScopeLength         = 50
+	MaxDataStoreEntryKeyLength      = 50
+	MaxDataStoreEntryValueSize      = 4_194_304
+	MaxDataStoreEntryUsers          = 4
+	MaxDataStoreEntryAttributes     = 300
+	MaxDataStoreEntryAttributeCount = 50
+	MaxMemoryStoreItemKeyLength     = 128
+	MaxMemoryStoreItemValueSize     = 32_768
+	MaxMemoryStoreItemTTL           = 45 * 24 * time.Hour
+	MaxUniverseMessageTopicSize     = 80
+	MaxUniverseMessageSize          = 1_024
+	MaxUserNotificationLaunchData   = 200
+)
+
+// Validator is implemented by request bodies that can check the documented API limits before they are sent.
+type Validator interface {
+	Validate() error
+}
+
+type ValidationViolation struct {
+	Field   string
+	Message string
+}
+
+// ValidationError is returned by Validate, and lists every limit the request violates.
+type ValidationError struct {
+	Violations []ValidationViolation
+}
+
+func (e *ValidationError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		violations[i] = fmt.Sprintf("%s: %s", violation.Field, violation.Message)
+	}
+
+	return fmt.Sprintf("invalid request: %s", strings.Join(violations, "; "))
+}
+
+// WithRequestValidation will validate request bodies that implement Validator, and data store keys and scopes,
+// before they are sent. Requests that fail validation return a ValidationError without a round trip.
+func WithRequestValidation() ClientOpts {
+	return func(c *Client) {
+		c.validateRequests = true
+	}
+}
+
+type validation struct {
+	violations []ValidationViolation
+}
+
+func (v *validation) add(field, format string, args ...any) {
+	v.violations = append(v.violations, ValidationViolation{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validation) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: v.violations}
+}
+
+func (v *validation) length(field, value string, limit int) {
+	if n := utf8.RuneCountInString(value); n > limit {
+		v.add(field, "length %d exceeds the limit of %d characters", n, limit)
+	}
+}
+
+func (v *validation) required(field, value string) {
+	if value == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validation) jsonSize(field string, value any, limit int) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		v.add(field, "can not be encoded as JSON: %s", err)
+		return
+	}
+	if len(b) > limit {
+		v.add(field, "size %d bytes exceeds the limit of %d bytes", len(b), limit)
+	}
+}
+
+func (v *validation) users(field string, users *[]string) {
+	if users != nil && len(*users) > MaxDataStoreEntryUsers {
+		v.add(field, "%d users exceeds the limit of %d", len(*users), MaxDataStoreEntryUsers)
+	}
+}
+
+func (v *validation) attributes(field string, attributes *map[string]any) {
+	if attributes == nil {
+		return
+	}
+
+	if n := len(*attributes); n > MaxDataStoreEntryAttributeCount {
+		v.add(field, "%d attributes exceeds the limit of %d", n, MaxDataStoreEntryAttributeCount)
+	}
+	v.jsonSize(field, *attributes, MaxDataStoreEntryAttributes)
+}
+
+// ttl will check a TTL in the duration format used by the API, such as "3600s".
+func (v *validation) ttl(field string, ttl *string) {
+	if ttl == nil {
+		return
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSuffix(*ttl, "s"), 64)
+	if err != nil || !strings.HasSuffix(*ttl, "s") {
+		v.add(field, "%q must be a duration in seconds, such as \"3600s\"", *ttl)
+		return
+	}
+
+	d := time.Duration(seconds * float64(time.Second))
+	if d <= 0 || d > MaxMemoryStoreItemTTL {
+		v.add(field, "%s must be greater than 0s and at most %s", d, MaxMemoryStoreItemTTL)
+	}
+}
+
+// ValidateDataStoreEntryPath will check the names used to address a data store entry.
+func ValidateDataStoreEntryPath(dataStoreId string, scope *string, entryId string) error {
+	v := new(validation)
+
+	v.required("dataStoreId", dataStoreId)
+	v.length("dataStoreId", dataStoreId, MaxDataStoreNameLength)
+	if scope != nil {
+		v.required("scope", *scope)
+		v.length("scope", *scope, MaxDataStoreScopeLength)
+		if strings.Contains(*scope, "/") {
+			v.add("scope", "can not contain \"/\"")
+		}
+	}
+	v.required("entryId", entryId)
+	v.length("entryId", entryId, MaxDataStoreEntryKeyLength)
+
+	return v.err()
+}
+
+// validateDataStoreEntryPath will run ValidateDataStoreEntryPath when the client has request validation enabled.
+func (c *Client) validateDataStoreEntryPath(dataStoreId string, scope *string, entryId string) error {
+	if !c.validateRequests {
+		return nil
+	}
+
+	return ValidateDataStoreEntryPath(dataStoreId, scope, entryId)
+}
+
+// Validate will check the entry against the data store limits.
+func (d DataStoreEntryCreate) Validate() error {
+	v := new(validation)
+
+	if d.Value == nil {
+		v.add("value", "is required")
+	} else {
+		v.jsonSize("value", *d.Value, MaxDataStoreEntryValueSize)
+	}
+	v.users("users", d.Users)
+	v.attributes("attributes", d.Attributes)
+
+	return v.err()
+}
+
+// Validate will check the entry against the data store limits.
+func (d DataStoreEntryUpdate) Validate() error {
+	v := new(validation)
+
+	if d.Value != nil {
+		v.jsonSize("value", *d.Value, MaxDataStoreEntryValueSize)
+	}
+	v.users("users", d.Users)
+	v.attributes("attributes", d.Attributes)
+
+	return v.err()
+}
+
+// Validate will check the item against the memory store limits.
+func (d MemoryStoreQueueItemCreate) Validate() error {
+	v := new(validation)
+
+	if d.Data == nil {
+		v.add("data", "is required")
+	} else {
+		v.jsonSize("data", *d.Data, MaxMemoryStoreItemValueSize)
+	}
+	v.ttl("ttl", d.TTL)
+
+	return v.err()
+}
+
+// Validate will check the item against the memory store limits.
+func (d MemoryStoreSortedMapItemCreate) Validate() error {
+	v := new(validation)
+
+	if d.Value == nil {
+		v.add("value", "is required")
+	} else {
+		v.jsonSize("value", *d.Value, MaxMemoryStoreItemValueSize)
+	}
+	v.ttl("ttl", d.TTL)
+	if d.ID != nil {
+		v.required("id", *d.ID)
+		v.length("id", *d.ID, MaxMemoryStoreItemKeyLength)
+	}
+	if d.StringSortKey != nil {
+		v.length("stringSortKey", *d.StringSortKey, MaxMemoryStoreItemKeyLength)
+	}
+
+	return v.err()
+}
+
+// Validate will check the message against the messaging service limits.
+func (d UniverseMessage) Validate() error {
+	v := new(validation)
+
+	v.required("topic", d.Topic)
+	v.length("topic", d.Topic, MaxUniverseMessageTopicSize)
+	if len(d.Message) > MaxUniverseMessageSize {
+		v.add("message", "size %d bytes exceeds the limit of %d bytes", len(d.Message), MaxUniverseMessageSize)
+	}
+
+	return v.err()
+}
+
+// Validate will check the notification against the notification limits.
+func (d UserNotificationCreate) Validate() error {
+	v := new(validation)
+
+	if d.Source == nil {
+		v.add("source", "is required")
+	} else {
+		v.required("source.universe", d.Source.Universe)
+	}
+
+	if d.Payload == nil {
+		v.add("payload", "is required")
+	} else {
+		if d.Payload.Type == "" || d.Payload.Type == UserNotificationTypeUnspecified {
+			v.add("payload.type", "is required")
+		}
+		v.required("payload.messageId", d.Payload.MessageID)
+		if n := len(d.Payload.JoinExperience.LaunchData); n > MaxUserNotificationLaunchData {
+			v.add("payload.joinExperience.launchData", "size %d bytes exceeds the limit of %d bytes", n, MaxUserNotificationLaunchData)
+		}
+	}
+
+	return v.err()
+}
+
+// Validate will check the item against the memory store limits.
+func (d MemoryStoreHashMapItemCreate) Validate() error {
+	v := new(validation)
+
+	if d.Value == nil {
+		v.add("value", "is required")
+	} else {
+		v.jsonSize("value", *d.Value, MaxMemoryStoreItemValueSize)
+	}
+	v.ttl("ttl", d.TTL)
+	if d.ID != nil {
+		v.required("id", *d.ID)
+		v.length("id", *d.ID, MaxMemoryStoreItemKeyLength)
+	}
+
+	return v.err()
+}